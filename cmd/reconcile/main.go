@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"robin-camp/internal/config"
+	"robin-camp/internal/database"
+	"robin-camp/internal/repository"
+)
+
+// reconcile recomputes movie_rating_stats from the ratings table and prints
+// every movie whose materialized aggregate has drifted. Run with -apply to
+// rewrite the drifted rows; exits with status 1 if drift was found and left
+// unrepaired.
+func main() {
+	loader := config.NewLoader(flag.CommandLine)
+	apply := flag.Bool("apply", false, "repair drifted rows instead of only reporting them")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Connect to database
+	db, err := database.Connect(cfg.DatabaseURL, database.PoolOptions{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Reconciliation reads and writes the primary only
+	ratingRepo := repository.NewRatingRepository(db, nil)
+
+	drifts, err := ratingRepo.ReconcileStats(*apply)
+	if err != nil {
+		log.Fatalf("Failed to reconcile rating stats: %v", err)
+	}
+
+	for _, d := range drifts {
+		fmt.Printf("%s: expected sum=%.1f count=%d buckets=%v, actual sum=%.1f count=%d buckets=%v\n",
+			d.MovieID,
+			d.Expected.Sum, d.Expected.Count, d.Expected.Buckets,
+			d.Actual.Sum, d.Actual.Count, d.Actual.Buckets)
+	}
+
+	switch {
+	case len(drifts) == 0:
+		fmt.Println("No drift found")
+	case *apply:
+		fmt.Printf("Repaired %d movie(s)\n", len(drifts))
+	default:
+		fmt.Printf("Found drift in %d movie(s); rerun with -apply to repair\n", len(drifts))
+		os.Exit(1)
+	}
+}
@@ -1,58 +1,320 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"robin-camp/internal/api"
 	"robin-camp/internal/api/handlers"
+	"robin-camp/internal/api/middleware"
+	"robin-camp/internal/auth"
 	"robin-camp/internal/client"
 	"robin-camp/internal/config"
 	"robin-camp/internal/database"
+	"robin-camp/internal/httpconf"
+	"robin-camp/internal/logging"
+	"robin-camp/internal/metrics"
+	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
+	"robin-camp/internal/ratelimit"
 	"robin-camp/internal/repository"
 	"robin-camp/internal/service"
+	"robin-camp/internal/tracing"
 )
 
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	// Load configuration: defaults < config file < environment < flags
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
+	cfg, err := loader.Load()
+	if loader.PrintConfig() && cfg != nil {
+		if printErr := cfg.Print(os.Stdout); printErr != nil {
+			log.Fatalf("Failed to print configuration: %v", printErr)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Set up logging; the standard log package writes through it as well
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	// Set up tracing
+	var spanExporter tracing.Exporter
+	switch cfg.TracingExporter {
+	case "stdout":
+		spanExporter = tracing.NewStdoutExporter(os.Stdout)
+	case "otlp":
+		spanExporter = tracing.NewOTLPExporter(cfg.TracingOTLPEndpoint, cfg.TracingServiceName)
+	}
+	var tracer *tracing.Tracer
+	if spanExporter != nil {
+		tracer = tracing.NewTracer(spanExporter, cfg.TracingSampleRatio)
+		tracer.Start(context.Background())
+		tracing.SetTracer(tracer)
+	}
 
 	// Connect to database
-	db, err := database.Connect(cfg.DatabaseURL)
+	pool := database.PoolOptions{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+	}
+	db, err := database.Connect(cfg.DatabaseURL, pool)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
+	// Connect to the read replica, if any; lag-tolerant reads go there
+	var replica *sql.DB
+	if cfg.DatabaseReplicaURL != "" {
+		replica, err = database.Connect(cfg.DatabaseReplicaURL, pool)
+		if err != nil {
+			log.Fatalf("Failed to connect to read replica: %v", err)
+		}
+		defer replica.Close()
+	}
+
 	// Run migrations
 	if err := database.RunMigrations(db, "./migrations"); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
+	metrics.RegisterDBStats(db, "db")
+	if replica != nil {
+		metrics.RegisterDBStats(replica, "db_replica")
+	}
 
 	// Initialize repositories
-	movieRepo := repository.NewMovieRepository(db)
-	ratingRepo := repository.NewRatingRepository(db)
+	movieRepo := repository.NewMovieRepository(db, replica)
+	ratingRepo := repository.NewRatingRepository(db, replica)
+	neighborRepo := repository.NewNeighborRepository(db, replica)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
 
 	// Initialize clients
-	boxOfficeClient := client.NewBoxOfficeClient(cfg.BoxOfficeURL, cfg.BoxOfficeAPIKey)
+	boxOfficeClient := client.NewBoxOfficeClient(cfg.BoxOfficeURL, cfg.BoxOfficeAPIKey, cfg.BoxOfficeTimeout)
+
+	// Load moderation filters
+	blocklist, err := moderation.LoadBlocklist(cfg.ModerationBlocklistFile)
+	if err != nil {
+		log.Fatalf("Failed to load moderation blocklist: %v", err)
+	}
+	moderator := moderation.NewPipeline(cfg.ModerationAutoApprove,
+		blocklist, moderation.NewSpamFilter(cfg.ModerationMaxLinks))
+
+	// Set up admin authentication
+	var adminJWT *auth.JWTVerifier
+	if cfg.AdminJWTSecret != "" || cfg.AdminJWKSFile != "" {
+		var jwks []auth.VerificationKey
+		if cfg.AdminJWKSFile != "" {
+			jwks, err = auth.LoadJWKS(cfg.AdminJWKSFile)
+			if err != nil {
+				log.Fatalf("Failed to load admin JWKS: %v", err)
+			}
+		}
+		adminJWT, err = auth.NewJWTVerifier(cfg.AdminJWTSecret, jwks, cfg.AdminJWTIssuer, cfg.AdminJWTAudience)
+		if err != nil {
+			log.Fatalf("Invalid admin JWT configuration: %v", err)
+		}
+	}
+	auditService := service.NewAuditService(auditRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, auditService)
+	adminAuth := auth.NewAdminAuthenticator(cfg.AuthToken, adminJWT, apiKeyService)
+
+	// Load rater token keys
+	var raterTokens *auth.Keyring
+	if cfg.RaterTokenKeys != "" {
+		keys, err := auth.ParseKeys(cfg.RaterTokenKeys)
+		if err != nil {
+			log.Fatalf("Invalid RATER_TOKEN_KEYS: %v", err)
+		}
+		raterTokens, err = auth.NewKeyring(keys, cfg.RaterTokenSigningKey, cfg.RaterTokenTTL)
+		if err != nil {
+			log.Fatalf("Invalid rater token configuration: %v", err)
+		}
+	}
+
+	// Set up rate limiting
+	trustedProxies, err := httpconf.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	rateLimitPolicy, err := newRateLimitPolicy(cfg)
+	if err != nil {
+		log.Fatalf("Invalid rate limits: %v", err)
+	}
+	var rateLimitStore ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		rateLimitStore = ratelimit.NewPostgresStore(db)
+	}
+	limiter := ratelimit.NewLimiter(rateLimitStore, rateLimitPolicy)
+
+	// Set up CORS
+	corsPolicy, err := httpconf.ParseCORSPolicy(cfg.CORSAllowedOrigins, cfg.CORSCredentialedOrigins, cfg.CORSMaxAge)
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+
+	// Set up request body limits and response compression
+	maxBodySize, err := httpconf.ParseByteSize(cfg.RequestMaxBodySize)
+	if err != nil {
+		log.Fatalf("Invalid REQUEST_MAX_BODY_SIZE: %v", err)
+	}
+	bodyLimits, err := httpconf.ParseBodyLimits(cfg.RequestBodyLimitRoutes)
+	if err != nil {
+		log.Fatalf("Invalid REQUEST_BODY_LIMIT_ROUTES: %v", err)
+	}
+	compress := func(next http.Handler) http.Handler { return next }
+	if cfg.CompressionEnabled {
+		compress = middleware.Compress(cfg.CompressionMinSize)
+	}
 
 	// Initialize services
-	movieService := service.NewMovieService(movieRepo, boxOfficeClient)
-	ratingService := service.NewRatingService(movieRepo, ratingRepo)
+	similarityWeights := models.SimilarityWeights{
+		Genre:       cfg.SimilarWeightGenre,
+		Distributor: cfg.SimilarWeightDistributor,
+		Era:         cfg.SimilarWeightEra,
+		MPARating:   cfg.SimilarWeightMPARating,
+		BudgetTier:  cfg.SimilarWeightBudgetTier,
+		CoRating:    cfg.SimilarWeightCoRating,
+	}
+	movieService := service.NewMovieService(movieRepo, boxOfficeClient, similarityWeights, auditService)
+	ratingPrior := models.RatingPrior{Mean: cfg.RatingPriorMean, MinVotes: cfg.RatingMinVotes}
+	ratingService := service.NewRatingService(movieRepo, ratingRepo, ratingPrior, cfg.TopMinCount, moderator)
+	reviewService := service.NewReviewService(movieRepo, ratingRepo, cfg.ModerationReportThreshold, auditService)
+	trendingService := service.NewTrendingService(ratingRepo, cfg.TrendingRefreshInterval)
+	recommendationService := service.NewRecommendationService(movieRepo, ratingRepo, neighborRepo,
+		cfg.RecommendationInterval, cfg.RecommendationNeighbors, cfg.RecommendationMinCoRaters)
+	anomalyThresholds := models.AnomalyThresholds{
+		Lookback:         cfg.AnomalyLookback,
+		BurstWindow:      cfg.AnomalyBurstWindow,
+		BurstMinRaters:   cfg.AnomalyBurstMinRaters,
+		ExtremeMinRaters: cfg.AnomalyExtremeMinRaters,
+	}
+	anomalyService := service.NewAnomalyService(ratingRepo, cfg.AnomalyScanInterval, anomalyThresholds, auditService)
+
+	// Start background jobs
+	trendingService.Start(context.Background())
+	if cfg.RecommendationsEnabled {
+		recommendationService.Start(context.Background())
+	}
+	if cfg.AnomalyDetectionEnabled {
+		anomalyService.Start(context.Background())
+	}
+	limiter.Start(context.Background())
 
 	// Initialize handlers
 	movieHandler := handlers.NewMovieHandler(movieService)
 	ratingHandler := handlers.NewRatingHandler(ratingService)
+	trendingHandler := handlers.NewTrendingHandler(trendingService)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	anomalyHandler := handlers.NewAnomalyHandler(anomalyService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 	healthHandler := handlers.NewHealthHandler()
+	var raterTokenHandler *handlers.RaterTokenHandler
+	if raterTokens != nil && raterTokens.CanIssue() {
+		raterTokenHandler = handlers.NewRaterTokenHandler(service.NewRaterTokenService(raterTokens, auditService), adminAuth)
+	}
 
 	// Setup router
-	router := api.SetupRouter(movieHandler, ratingHandler, healthHandler, cfg.AuthToken)
+	router := api.SetupRouter(movieHandler, ratingHandler, trendingHandler, recommendationHandler, reviewHandler,
+		anomalyHandler, apiKeyHandler, auditHandler, raterTokenHandler, healthHandler, adminAuth, cfg.RaterAuthMode, raterTokens,
+		trustedProxies, middleware.RateLimit(limiter, adminAuth, raterTokens), corsPolicy,
+		middleware.BodyLimit(bodyLimits, maxBodySize), compress)
+
+	// Apply the reloadable settings on SIGHUP
+	reloadOnHangup(loader, cfg, limiter, tracer)
 
 	// Start server
-	addr := fmt.Sprintf("0.0.0.0:%s", cfg.Port)
-	log.Printf("Starting server on %s", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
+	server := &http.Server{
+		Addr:              fmt.Sprintf("0.0.0.0:%s", cfg.Port),
+		Handler:           router,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	slog.Info("starting server", "addr", server.Addr)
+	if err := server.ListenAndServe(); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
+
+func newRateLimitPolicy(cfg *config.Config) (ratelimit.Policy, error) {
+	defaultLimit, err := ratelimit.ParseLimit(cfg.RateLimitDefault)
+	if err != nil {
+		return ratelimit.Policy{}, err
+	}
+	routeLimits, err := ratelimit.ParseRoutes(cfg.RateLimitRoutes)
+	if err != nil {
+		return ratelimit.Policy{}, err
+	}
+	return ratelimit.Policy{Routes: routeLimits, Default: defaultLimit}, nil
+}
+
+// reloadOnHangup re-reads the configuration on each SIGHUP and applies the
+// settings that are safe to change at runtime: log level, rate limits and
+// the trace sample ratio. An invalid configuration is rejected as a whole;
+// other changed settings are logged as needing a restart.
+func reloadOnHangup(loader *config.Loader, cfg *config.Config, limiter *ratelimit.Limiter, tracer *tracing.Tracer) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		for range hangup {
+			next, err := loader.Load()
+			if err != nil {
+				slog.Error("config reload failed, keeping current configuration", "error", err)
+				continue
+			}
+			reloadable, restart := config.Changed(cfg, next)
+			if len(restart) > 0 {
+				slog.Warn("changed settings need a restart to take effect", "keys", restart)
+			}
+
+			policy, err := newRateLimitPolicy(next)
+			if err != nil {
+				slog.Error("config reload failed, keeping current configuration", "error", err)
+				continue
+			}
+			if err := logging.SetLevel(next.LogLevel); err != nil {
+				slog.Error("config reload failed, keeping current configuration", "error", err)
+				continue
+			}
+			limiter.SetPolicy(policy)
+			if tracer != nil {
+				tracer.SetSampleRatio(next.TracingSampleRatio)
+			}
+
+			cfg.LogLevel = next.LogLevel
+			cfg.RateLimitDefault = next.RateLimitDefault
+			cfg.RateLimitRoutes = next.RateLimitRoutes
+			cfg.TracingSampleRatio = next.TracingSampleRatio
+			slog.Info("config reloaded", "applied", reloadable)
+		}
+	}()
+}
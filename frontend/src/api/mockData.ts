@@ -4,6 +4,7 @@ export const MOCK_MOVIES: Movie[] = [
     {
         id: 'm_1',
         title: 'Inception',
+        slug: 'inception',
         genre: 'Sci-Fi',
         releaseDate: '2010-07-16',
         distributor: 'Warner Bros.',
@@ -22,6 +23,7 @@ export const MOCK_MOVIES: Movie[] = [
     {
         id: 'm_2',
         title: 'The Dark Knight',
+        slug: 'the-dark-knight',
         genre: 'Action',
         releaseDate: '2008-07-18',
         distributor: 'Warner Bros.',
@@ -40,6 +42,7 @@ export const MOCK_MOVIES: Movie[] = [
     {
         id: 'm_3',
         title: 'Interstellar',
+        slug: 'interstellar',
         genre: 'Sci-Fi',
         releaseDate: '2014-11-07',
         distributor: 'Paramount',
@@ -58,6 +61,7 @@ export const MOCK_MOVIES: Movie[] = [
     {
         id: 'm_4',
         title: 'Dune: Part Two',
+        slug: 'dune-part-two',
         genre: 'Sci-Fi',
         releaseDate: '2024-03-01',
         distributor: 'Warner Bros.',
@@ -1,8 +1,8 @@
 import { client } from './client';
 import type { RatingAggregate, RatingResult, RatingSubmit } from '../types';
 
-export const submitRating = async (title: string, rating: RatingSubmit, raterId: string) => {
-    const response = await client.post<RatingResult>(`/movies/${title}/ratings`, rating, {
+export const submitRating = async (slug: string, rating: RatingSubmit, raterId: string) => {
+    const response = await client.post<RatingResult>(`/movies/${encodeURIComponent(slug)}/ratings`, rating, {
         headers: {
             'X-Rater-Id': raterId,
         },
@@ -10,7 +10,7 @@ export const submitRating = async (title: string, rating: RatingSubmit, raterId:
     return response.data;
 };
 
-export const getRating = async (title: string) => {
-    const response = await client.get<RatingAggregate>(`/movies/${title}/rating`);
+export const getRating = async (slug: string) => {
+    const response = await client.get<RatingAggregate>(`/movies/${encodeURIComponent(slug)}/rating`);
     return response.data;
 };
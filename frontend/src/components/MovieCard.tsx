@@ -12,8 +12,8 @@ export const MovieCard: FC<MovieCardProps> = ({ movie }) => {
     const [rating, setRating] = useState<RatingAggregate | null>(null);
 
     useEffect(() => {
-        getRating(movie.title).then(setRating).catch(() => setRating(null));
-    }, [movie.title]);
+        getRating(movie.slug).then(setRating).catch(() => setRating(null));
+    }, [movie.slug]);
 
     const formatMoney = (amount: number) => {
         if (amount >= 1000000000) {
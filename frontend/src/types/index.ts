@@ -11,6 +11,7 @@ export interface BoxOffice {
 export interface Movie {
     id: string;
     title: string;
+    slug: string;
     releaseDate: string;
     genre: string;
     distributor?: string;
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"robin-camp/internal/api/middleware"
+	"robin-camp/internal/service"
+)
+
+type AnomalyHandler struct {
+	anomalyService *service.AnomalyService
+}
+
+func NewAnomalyHandler(anomalyService *service.AnomalyService) *AnomalyHandler {
+	return &AnomalyHandler{anomalyService: anomalyService}
+}
+
+func (h *AnomalyHandler) ListAnomalies(w http.ResponseWriter, r *http.Request) {
+	status := "quarantined"
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		status = statusStr
+	}
+
+	limit := 20 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	page, err := h.anomalyService.ListAnomalies(status, limit, cursor)
+	if err != nil {
+		switch err.Error() {
+		case "invalid status":
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid status parameter")
+		case "invalid cursor":
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *AnomalyHandler) GetAnomaly(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseAnomalyID(w, r)
+	if !ok {
+		return
+	}
+
+	anomaly, err := h.anomalyService.GetAnomaly(id)
+	if err != nil {
+		respondAnomalyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anomaly)
+}
+
+func (h *AnomalyHandler) ReleaseAnomaly(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseAnomalyID(w, r)
+	if !ok {
+		return
+	}
+
+	anomaly, err := h.anomalyService.ReleaseAnomaly(r.Context(), id, middleware.AdminActor(r))
+	if err != nil {
+		respondAnomalyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anomaly)
+}
+
+func parseAnomalyID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id < 1 {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Anomaly not found")
+		return 0, false
+	}
+	return id, true
+}
+
+func respondAnomalyError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "anomaly not found":
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Anomaly not found")
+	case "anomaly already released":
+		respondError(w, http.StatusConflict, "CONFLICT", "Anomaly has already been released")
+	default:
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"robin-camp/internal/api/middleware"
+	"robin-camp/internal/models"
+	"robin-camp/internal/service"
+)
+
+// maxAPIKeyNameLength matches the api_keys.name column.
+const maxAPIKeyNameLength = 100
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req models.APIKeyCreate
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxAPIKeyNameLength {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Name is required and must be at most 100 characters")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "At least one scope is required")
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(r.Context(), req, middleware.AdminClaims(r), middleware.AdminActor(r))
+	if err != nil {
+		switch err.Error() {
+		case "unknown scope":
+			respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Unknown scope")
+		case "expiry in the past":
+			respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Expiry must be in the future")
+		case "scope not held":
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Cannot grant a scope you do not hold")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListKeys()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id < 1 {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "API key not found")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(r.Context(), id, middleware.AdminActor(r)); err != nil {
+		if err.Error() == "api key not found" {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "API key not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"robin-camp/internal/service"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	filters := make(map[string]interface{})
+
+	// Parse query parameters
+	if actor := r.URL.Query().Get("actor"); actor != "" {
+		filters["actor"] = actor
+	}
+
+	if target := r.URL.Query().Get("target"); target != "" {
+		filters["target"] = target
+	}
+
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid since parameter")
+			return
+		}
+		filters["since"] = since.UTC()
+	}
+
+	limit := 20 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	page, err := h.auditService.ListAudit(filters, limit, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
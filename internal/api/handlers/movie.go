@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"github.com/gorilla/mux"
+	"robin-camp/internal/api/middleware"
 	"robin-camp/internal/models"
 	"robin-camp/internal/service"
 )
@@ -20,8 +24,7 @@ func NewMovieHandler(movieService *service.MovieService) *MovieHandler {
 
 func (h *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 	var req models.MovieCreate
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Invalid request body")
+	if !decodeBody(w, r, &req) {
 		return
 	}
 
@@ -31,19 +34,60 @@ func (h *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	movie, err := h.movieService.CreateMovie(&req)
+	movie, err := h.movieService.CreateMovie(r.Context(), &req, middleware.AdminActor(r))
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		switch err.Error() {
+		case "invalid release date":
+			respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Invalid releaseDate, want YYYY-MM-DD")
+		case "movie already exists":
+			respondError(w, http.StatusConflict, "CONFLICT", "A movie with this title was already released that year")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
 		return
 	}
 
 	// Set Location header
-	w.Header().Set("Location", fmt.Sprintf("/movies/%s", movie.Title))
+	w.Header().Set("Location", "/movies/"+url.PathEscape(movie.Slug))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(movie)
 }
 
+// GetMovie serves /movies/{slug}, which also accepts an exact title and a
+// year parameter to pick among remakes.
+func (h *MovieHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "slug")
+	if !ok {
+		return
+	}
+	movie, err := h.movieService.GetMovie(ref)
+	respondMovie(w, movie, err)
+}
+
+// GetMovieByID serves /movies/id/{id}.
+func (h *MovieHandler) GetMovieByID(w http.ResponseWriter, r *http.Request) {
+	movie, err := h.movieService.GetMovieByID(mux.Vars(r)["id"])
+	respondMovie(w, movie, err)
+}
+
+func respondMovie(w http.ResponseWriter, movie *models.Movie, err error) {
+	if respondAmbiguous(w, err) {
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	if movie == nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie)
+}
+
 func (h *MovieHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 	filters := make(map[string]interface{})
 
@@ -82,6 +126,24 @@ func (h *MovieHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 		filters["mpaRating"] = mpaRating
 	}
 
+	if minRatingStr := r.URL.Query().Get("minRating"); minRatingStr != "" {
+		minRating, err := strconv.ParseFloat(minRatingStr, 64)
+		if err != nil || minRating < 0 || minRating > 5 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid minRating parameter")
+			return
+		}
+		filters["minRating"] = minRating
+	}
+
+	switch sort := r.URL.Query().Get("sort"); sort {
+	case "", "id":
+	case "rating":
+		filters["sort"] = sort
+	default:
+		respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid sort parameter")
+		return
+	}
+
 	limit := 20 // Default limit
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		parsedLimit, err := strconv.Atoi(limitStr)
@@ -96,6 +158,10 @@ func (h *MovieHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 
 	page, err := h.movieService.ListMovies(filters, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+			return
+		}
 		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 		return
 	}
@@ -104,11 +170,109 @@ func (h *MovieHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(page)
 }
 
+func (h *MovieHandler) SimilarMovies(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+
+	limit := 10 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 || parsedLimit > 100 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	similar, err := h.movieService.SimilarMovies(ref, limit)
+	if err != nil {
+		if respondAmbiguous(w, err) {
+			return
+		}
+		if err.Error() == "movie not found" {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(similar)
+}
+
+// movieRef reads a movie reference from the named path variable and the
+// optional year query parameter. It responds 400 when the year is invalid.
+func movieRef(w http.ResponseWriter, r *http.Request, name string) (models.MovieRef, bool) {
+	ref := models.MovieRef{Ref: mux.Vars(r)[name]}
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid year parameter")
+			return ref, false
+		}
+		ref.Year = year
+	}
+	return ref, true
+}
+
+// respondAmbiguous responds 300 Multiple Choices, listing where each
+// candidate can be found, when err reports a title shared by several
+// movies. Choices point at /movies/id/{id}, which cannot be ambiguous even
+// when a candidate's slug equals the title. It reports whether it responded.
+func respondAmbiguous(w http.ResponseWriter, err error) bool {
+	var ambiguous *service.AmbiguousMovieError
+	if !errors.As(err, &ambiguous) {
+		return false
+	}
+
+	choices := make([]models.MovieChoice, 0, len(ambiguous.Candidates))
+	for _, movie := range ambiguous.Candidates {
+		choices = append(choices, models.MovieChoice{
+			ID:          movie.ID,
+			Slug:        movie.Slug,
+			Title:       movie.Title,
+			ReleaseDate: movie.ReleaseDate,
+			Location:    "/movies/id/" + url.PathEscape(movie.ID),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMultipleChoices)
+	json.NewEncoder(w).Encode(models.Error{
+		Code:      "AMBIGUOUS",
+		Message:   fmt.Sprintf("%q matches %d movies; use a slug or the year parameter", ambiguous.Title, len(choices)),
+		Details:   choices,
+		RequestID: w.Header().Get("X-Request-Id"),
+	})
+	return true
+}
+
+// decodeBody decodes the JSON request body into v. It responds 413 when the
+// body exceeds the route's size limit and 422 when it is malformed.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondError(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit))
+		return false
+	}
+	respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Invalid request body")
+	return false
+}
+
 func respondError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
+	// The request id middleware has already set the response header
 	json.NewEncoder(w).Encode(models.Error{
-		Code:    code,
-		Message: message,
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get("X-Request-Id"),
 	})
 }
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"robin-camp/internal/api/middleware"
+	"robin-camp/internal/auth"
+	"robin-camp/internal/models"
+	"robin-camp/internal/service"
+)
+
+// maxRaterIDLength matches the ratings.rater_id column.
+const maxRaterIDLength = 100
+
+type RaterTokenHandler struct {
+	tokenService *service.RaterTokenService
+	admin        *auth.AdminAuthenticator
+}
+
+func NewRaterTokenHandler(tokenService *service.RaterTokenService, admin *auth.AdminAuthenticator) *RaterTokenHandler {
+	return &RaterTokenHandler{tokenService: tokenService, admin: admin}
+}
+
+// IssueToken hands out rater tokens. Without credentials it creates a new
+// rater identity; with a valid rater token it renews that rater's token;
+// with an admin token it issues a token for the requested rater id.
+func (h *RaterTokenHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	var req models.RaterTokenRequest
+	if r.ContentLength != 0 {
+		if !decodeBody(w, r, &req) {
+			return
+		}
+	}
+	req.RaterID = strings.TrimSpace(req.RaterID)
+	if len(req.RaterID) > maxRaterIDLength {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Rater id is too long")
+		return
+	}
+
+	var raterID string
+	var actor *models.Actor
+	bearer, hasBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	admin, adminErr := h.admin.Authenticate(r.Context(), bearer)
+	switch {
+	case hasBearer && adminErr == nil:
+		if !admin.HasScope(auth.ScopeRatersIssue) {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Missing required scope "+auth.ScopeRatersIssue)
+			return
+		}
+		if req.RaterID == "" {
+			respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Rater id is required")
+			return
+		}
+		raterID = req.RaterID
+		issuer := middleware.RequestActor(r, admin.Subject)
+		actor = &issuer
+	case hasBearer:
+		subject, err := h.tokenService.VerifyToken(bearer)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid rater token")
+			return
+		}
+		if req.RaterID != "" && req.RaterID != subject {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Cannot issue a token for another rater")
+			return
+		}
+		raterID = subject
+	case req.RaterID != "":
+		respondError(w, http.StatusForbidden, "FORBIDDEN", "Choosing a rater id requires authorization")
+		return
+	}
+
+	token, err := h.tokenService.IssueToken(r.Context(), raterID, actor)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(token)
+}
@@ -2,9 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
+	"robin-camp/internal/api/middleware"
 	"robin-camp/internal/models"
 	"robin-camp/internal/service"
 )
@@ -18,21 +24,21 @@ func NewRatingHandler(ratingService *service.RatingService) *RatingHandler {
 }
 
 func (h *RatingHandler) SubmitRating(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	title := vars["title"]
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
 
-	raterID := r.Header.Get("X-Rater-Id")
+	raterID := middleware.RaterID(r)
 
 	var req models.RatingSubmit
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Invalid request body")
+	if !decodeBody(w, r, &req) {
 		return
 	}
 
 	// Validate rating value
-	validRatings := []float64{0.5, 1.0, 1.5, 2.0, 2.5, 3.0, 3.5, 4.0, 4.5, 5.0}
 	valid := false
-	for _, v := range validRatings {
+	for _, v := range models.RatingScale {
 		if req.Rating == v {
 			valid = true
 			break
@@ -43,8 +49,17 @@ func (h *RatingHandler) SubmitRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rating, isNew, err := h.ratingService.SubmitRating(title, raterID, req.Rating)
+	review, message := parseReview(&req)
+	if message != "" {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", message)
+		return
+	}
+
+	rating, isNew, err := h.ratingService.SubmitRating(ref, raterID, req.Rating, review)
 	if err != nil {
+		if respondAmbiguous(w, err) {
+			return
+		}
 		if err.Error() == "movie not found" {
 			respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
 			return
@@ -56,7 +71,8 @@ func (h *RatingHandler) SubmitRating(w http.ResponseWriter, r *http.Request) {
 	status := http.StatusOK
 	if isNew {
 		status = http.StatusCreated
-		w.Header().Set("Location", "/movies/"+title+"/ratings")
+		// The slug is the movie's stable address, whatever ref found it
+		w.Header().Set("Location", "/movies/"+url.PathEscape(rating.MovieSlug)+"/ratings")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -64,12 +80,89 @@ func (h *RatingHandler) SubmitRating(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(rating)
 }
 
+// parseReview validates the optional review fields of a submission. It
+// returns nil when neither field is present, and a non-empty message when
+// the review is invalid.
+func parseReview(req *models.RatingSubmit) (*models.ReviewInput, string) {
+	if req.Headline == nil && req.Body == nil {
+		return nil, ""
+	}
+
+	review := &models.ReviewInput{}
+	if req.Body != nil {
+		if body := strings.TrimSpace(*req.Body); body != "" {
+			review.Body = &body
+		}
+	}
+	if req.Headline != nil {
+		if headline := strings.TrimSpace(*req.Headline); headline != "" {
+			review.Headline = &headline
+		}
+	}
+
+	if review.Body == nil {
+		if review.Headline != nil {
+			return nil, "Review headline requires a body"
+		}
+		return review, "" // Empty body removes the review
+	}
+	if utf8.RuneCountInString(*review.Body) > models.MaxReviewBodyLength {
+		return nil, fmt.Sprintf("Review body exceeds %d characters", models.MaxReviewBodyLength)
+	}
+	if review.Headline != nil && utf8.RuneCountInString(*review.Headline) > models.MaxReviewHeadlineLength {
+		return nil, fmt.Sprintf("Review headline exceeds %d characters", models.MaxReviewHeadlineLength)
+	}
+
+	return review, ""
+}
+
+func (h *RatingHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+
+	raterID := middleware.RaterID(r)
+
+	if err := h.ratingService.DeleteRating(ref, raterID); err != nil {
+		if respondAmbiguous(w, err) {
+			return
+		}
+		switch err.Error() {
+		case "movie not found":
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
+		case "rating not found":
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Rating not found")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *RatingHandler) GetRatingAggregate(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	title := vars["title"]
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+
+	detail := false
+	if detailStr := r.URL.Query().Get("detail"); detailStr != "" {
+		parsed, err := strconv.ParseBool(detailStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid detail parameter")
+			return
+		}
+		detail = parsed
+	}
 
-	aggregate, err := h.ratingService.GetRatingAggregate(title)
+	aggregate, err := h.ratingService.GetRatingAggregate(ref, detail)
 	if err != nil {
+		if respondAmbiguous(w, err) {
+			return
+		}
 		if err.Error() == "movie not found" {
 			respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
 			return
@@ -81,3 +174,137 @@ func (h *RatingHandler) GetRatingAggregate(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(aggregate)
 }
+
+func (h *RatingHandler) ListRaterRatings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	raterID := vars["raterId"]
+
+	sort := r.URL.Query().Get("sort")
+	switch sort {
+	case "":
+		sort = "newest"
+	case "newest", "oldest", "highest", "lowest":
+	default:
+		respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid sort parameter")
+		return
+	}
+
+	limit := 20 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	page, err := h.ratingService.ListRaterRatings(raterID, sort, limit, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *RatingHandler) TopRated(w http.ResponseWriter, r *http.Request) {
+	filters := make(map[string]interface{})
+
+	if genre := r.URL.Query().Get("genre"); genre != "" {
+		filters["genre"] = genre
+	}
+
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid year parameter")
+			return
+		}
+		filters["year"] = year
+	}
+
+	minCount := 0 // Use configured threshold
+	if minCountStr := r.URL.Query().Get("minCount"); minCountStr != "" {
+		parsed, err := strconv.Atoi(minCountStr)
+		if err != nil || parsed < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid minCount parameter")
+			return
+		}
+		minCount = parsed
+	}
+
+	limit := 10 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 || parsedLimit > 100 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	top, err := h.ratingService.TopRated(filters, minCount, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(top)
+}
+
+func (h *RatingHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+
+	sort := r.URL.Query().Get("sort")
+	switch sort {
+	case "":
+		sort = "newest"
+	case "newest", "highest", "helpful":
+	default:
+		respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid sort parameter")
+		return
+	}
+
+	limit := 20 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	page, err := h.ratingService.ListReviews(ref, sort, limit, cursor)
+	if err != nil {
+		if respondAmbiguous(w, err) {
+			return
+		}
+		switch err.Error() {
+		case "movie not found":
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
+		case "invalid cursor":
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
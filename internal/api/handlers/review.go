@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"robin-camp/internal/api/middleware"
+	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
+	"robin-camp/internal/service"
+)
+
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+func (h *ReviewHandler) VoteReview(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+	reviewerID := mux.Vars(r)["raterId"]
+
+	voterID := middleware.RaterID(r)
+
+	var req models.ReviewVoteSubmit
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	var helpful bool
+	switch req.Vote {
+	case "helpful":
+		helpful = true
+	case "unhelpful":
+	default:
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Invalid vote value")
+		return
+	}
+
+	vote, isNew, err := h.reviewService.VoteReview(ref, reviewerID, voterID, helpful)
+	if err != nil {
+		respondReviewError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if isNew {
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(vote)
+}
+
+func (h *ReviewHandler) ReportReview(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+	reviewerID := mux.Vars(r)["raterId"]
+
+	reporterID := middleware.RaterID(r)
+
+	var req models.ReviewReportSubmit
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	valid := false
+	for _, reason := range models.ReportReasons {
+		if req.Reason == reason {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "Invalid report reason")
+		return
+	}
+
+	var details *string
+	if req.Details != nil {
+		if trimmed := strings.TrimSpace(*req.Details); trimmed != "" {
+			if utf8.RuneCountInString(trimmed) > models.MaxReportDetailsLength {
+				respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST",
+					fmt.Sprintf("Report details exceed %d characters", models.MaxReportDetailsLength))
+				return
+			}
+			details = &trimmed
+		}
+	}
+
+	report, isNew, err := h.reviewService.ReportReview(ref, reviewerID, reporterID, req.Reason, details)
+	if err != nil {
+		respondReviewError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if isNew {
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ReviewHandler) ListReportedReviews(w http.ResponseWriter, r *http.Request) {
+	limit := 20 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	page, err := h.reviewService.ListReportedReviews(limit, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *ReviewHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.moderateReview(w, r, moderation.StatusApproved)
+}
+
+func (h *ReviewHandler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	h.moderateReview(w, r, moderation.StatusRejected)
+}
+
+func (h *ReviewHandler) moderateReview(w http.ResponseWriter, r *http.Request, toStatus string) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+	reviewerID := mux.Vars(r)["raterId"]
+
+	var req models.ModerationDecision
+	if r.ContentLength != 0 {
+		if !decodeBody(w, r, &req) {
+			return
+		}
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if toStatus == moderation.StatusRejected && reason == "" {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST", "A reason is required to reject a review")
+		return
+	}
+	if utf8.RuneCountInString(reason) > models.MaxReportDetailsLength {
+		respondError(w, http.StatusUnprocessableEntity, "BAD_REQUEST",
+			fmt.Sprintf("Reason exceeds %d characters", models.MaxReportDetailsLength))
+		return
+	}
+
+	event, err := h.reviewService.ModerateReview(r.Context(), ref, reviewerID, middleware.AdminActor(r), toStatus, reason)
+	if err != nil {
+		respondReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+func (h *ReviewHandler) ListModerationQueue(w http.ResponseWriter, r *http.Request) {
+	status := moderation.StatusPending
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		status = statusStr
+	}
+
+	limit := 20 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	queue, err := h.reviewService.ListModerationQueue(status, limit, cursor)
+	if err != nil {
+		switch err.Error() {
+		case "invalid status":
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid status parameter")
+		case "invalid cursor":
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor parameter")
+		default:
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queue)
+}
+
+func (h *ReviewHandler) GetModerationHistory(w http.ResponseWriter, r *http.Request) {
+	ref, ok := movieRef(w, r, "title")
+	if !ok {
+		return
+	}
+	reviewerID := mux.Vars(r)["raterId"]
+
+	history, err := h.reviewService.GetModerationHistory(ref, reviewerID)
+	if err != nil {
+		respondReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func respondReviewError(w http.ResponseWriter, err error) {
+	if respondAmbiguous(w, err) {
+		return
+	}
+	switch err.Error() {
+	case "movie not found":
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Movie not found")
+	case "review not found":
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Review not found")
+	case "cannot vote on own review":
+		respondError(w, http.StatusForbidden, "FORBIDDEN", "Cannot vote on your own review")
+	case "invalid transition":
+		respondError(w, http.StatusConflict, "CONFLICT", "Review cannot move to that status")
+	default:
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
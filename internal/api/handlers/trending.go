@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"robin-camp/internal/service"
+)
+
+type TrendingHandler struct {
+	trendingService *service.TrendingService
+}
+
+func NewTrendingHandler(trendingService *service.TrendingService) *TrendingHandler {
+	return &TrendingHandler{trendingService: trendingService}
+}
+
+func (h *TrendingHandler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = "7d"
+	}
+
+	weighted := false
+	if weightedStr := r.URL.Query().Get("weighted"); weightedStr != "" {
+		parsed, err := strconv.ParseBool(weightedStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid weighted parameter")
+			return
+		}
+		weighted = parsed
+	}
+
+	limit := 10 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 1 || parsedLimit > 100 {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid limit parameter")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	trending, err := h.trendingService.GetTrending(window, weighted, limit)
+	if err != nil {
+		if err.Error() == "invalid window" {
+			respondError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid window parameter")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trending)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// BodyLimit caps request bodies per route, keyed like RateLimit, falling
+// back to fallback. Bodies declared larger than the limit are rejected with
+// 413 up front; chunked bodies are cut off by http.MaxBytesReader, which
+// handlers turn into 413 when decoding. Write requests with a body must be
+// JSON, otherwise they are rejected with 415. It must be installed with
+// Router.Use so the matched route is known.
+func BodyLimit(routes map[string]int64, fallback int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			write := r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+			if write && !isJSON(r.Header.Get("Content-Type")) {
+				respondError(w, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "Content-Type must be application/json")
+				return
+			}
+
+			limit, ok := routes[routeKey(r)]
+			if !ok {
+				limit = fallback
+			}
+			if r.ContentLength > limit {
+				respondError(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", fmt.Sprintf("Request body exceeds %d bytes", limit))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isJSON accepts application/json and structured +json media types.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
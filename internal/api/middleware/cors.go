@@ -2,23 +2,138 @@ package middleware
 
 import (
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"robin-camp/internal/httpconf"
 )
 
-// CORS middleware to allow cross-origin requests from the frontend
-func CORS(next http.Handler) http.Handler {
+const (
+	corsAllowHeaders  = "Content-Type, Authorization, X-Rater-Id, X-Request-Id"
+	corsExposeHeaders = "X-Request-Id, RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After"
+)
+
+// CORS applies policy to browser requests carrying an Origin header.
+// Preflights are answered here with the methods router actually serves for
+// the path; preflights from disallowed origins, for unknown paths or for
+// methods the path does not serve are rejected. It must be installed with
+// Router.Use on router, which must route OPTIONS requests to Options.
+func CORS(policy httpconf.CORSPolicy, router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses without an Origin differ from those with one, so
+			// caches must key on it either way
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, credentials, anyOrigin := policy.Check(origin)
+			requestMethod := r.Header.Get("Access-Control-Request-Method")
+			preflight := r.Method == http.MethodOptions && requestMethod != ""
+
+			var methods []string
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				if !allowed {
+					respondError(w, http.StatusForbidden, "FORBIDDEN", "Origin not allowed")
+					return
+				}
+				methods = allowedMethods(router, r)
+				if len(methods) == 0 {
+					respondError(w, http.StatusNotFound, "NOT_FOUND", "Not found")
+					return
+				}
+				if !containsMethod(methods, requestMethod) {
+					respondError(w, http.StatusForbidden, "FORBIDDEN", "Method not allowed for this path")
+					return
+				}
+			}
+
+			if allowed {
+				if anyOrigin {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				if credentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			if preflight {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				if policy.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if allowed {
+				w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Options answers OPTIONS requests that are not CORS preflights with the
+// methods router serves for the path.
+func Options(router *mux.Router) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow requests from localhost:5173 (Vite dev server)
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Rater-Id")
-		w.Header().Set("Access-Control-Max-Age", "3600")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+		methods := allowedMethods(router, r)
+		if len(methods) == 0 {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Not found")
 			return
 		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
 
-		next.ServeHTTP(w, r)
+// allowedMethods returns the methods router has a route for at r's path,
+// plus OPTIONS, or nothing if the path is unknown.
+func allowedMethods(router *mux.Router, r *http.Request) []string {
+	candidates := make(map[string]bool)
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, _ := route.GetMethods()
+		for _, m := range methods {
+			if m != http.MethodOptions {
+				candidates[m] = true
+			}
+		}
+		return nil
 	})
+
+	var methods []string
+	for m := range candidates {
+		probe := r.Clone(r.Context())
+		probe.Method = m
+		var match mux.RouteMatch
+		if router.Match(probe, &match) && match.MatchErr == nil {
+			methods = append(methods, m)
+		}
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	sort.Strings(methods)
+	return append(methods, http.MethodOptions)
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
 }
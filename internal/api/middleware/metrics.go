@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"robin-camp/internal/metrics"
+)
+
+// Metrics counts requests and observes their latency by route template.
+// It must be installed with Router.Use so the matched route is known.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		status := strconv.Itoa(rec.status)
+		metrics.HTTPRequests.Inc(r.Method, route, status)
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), r.Method, route, status)
+	})
+}
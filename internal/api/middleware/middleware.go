@@ -1,36 +1,118 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
+	"robin-camp/internal/auth"
+	"robin-camp/internal/logging"
 	"robin-camp/internal/models"
 )
 
+// statusRecorder captures the status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// Logger writes one structured access log line per request using the
+// request-scoped logger.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		log.Printf("%s %s", r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s completed in %v", r.Method, r.URL.Path, time.Since(start))
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(r.Context()).Log(r.Context(), level, "request completed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", clientIP(r),
+		)
 	})
 }
 
-func AuthMiddleware(authToken string) func(http.Handler) http.Handler {
+// AuthMiddleware requires an admin bearer token, either a valid JWT or the
+// static token, and puts its claims on the request context. Claims already
+// verified by RateLimit are reused.
+func AuthMiddleware(authenticator *auth.AdminAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := r.Header.Get("Authorization")
-			if token == "" {
+			if AdminClaims(r) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := r.Header.Get("Authorization")
+			if header == "" {
 				respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Missing authorization header")
 				return
 			}
 
 			// Check Bearer token
-			expectedToken := "Bearer " + authToken
-			if token != expectedToken {
-				respondError(w, http.StatusForbidden, "FORBIDDEN", "Invalid authorization token")
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid authorization header")
+				return
+			}
+			claims, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				message := "Invalid authorization token"
+				if err.Error() == "token expired" {
+					message = "Authorization token expired"
+				}
+				respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", message)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+		})
+	}
+}
+
+// RequireScope rejects requests whose admin token lacks scope. It must run
+// after AuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := AdminClaims(r)
+			if claims == nil || !claims.HasScope(scope) {
+				respondError(w, http.StatusForbidden, "FORBIDDEN", "Missing required scope "+scope)
 				return
 			}
 
@@ -39,6 +121,29 @@ func AuthMiddleware(authToken string) func(http.Handler) http.Handler {
 	}
 }
 
+// AdminClaims returns the claims of the admin token, if any.
+func AdminClaims(r *http.Request) *auth.Claims {
+	claims, _ := r.Context().Value(claimsKey).(*auth.Claims)
+	return claims
+}
+
+// AdminActor describes the admin behind a request for audit records.
+func AdminActor(r *http.Request) models.Actor {
+	if claims := AdminClaims(r); claims != nil && claims.Subject != "" {
+		return RequestActor(r, claims.Subject)
+	}
+	return RequestActor(r, auth.StaticSubject)
+}
+
+// RequestActor attributes a request to id, recording where it came from.
+func RequestActor(r *http.Request, id string) models.Actor {
+	return models.Actor{
+		ID:        id,
+		RequestID: RequestID(r),
+		ClientIP:  clientIP(r),
+	}
+}
+
 func RaterIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		raterID := r.Header.Get("X-Rater-Id")
@@ -47,7 +152,7 @@ func RaterIDMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withRaterID(r, raterID))
 	})
 }
 
@@ -55,7 +160,8 @@ func respondError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(models.Error{
-		Code:    code,
-		Message: message,
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(RequestIDHeader),
 	})
 }
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"robin-camp/internal/auth"
+	"robin-camp/internal/ratelimit"
+)
+
+// RateLimit applies a token bucket per route and client, using the
+// limiter's current policy. Routes are keyed by "<METHOD> <path template>"
+// as registered on the router. Clients are identified by a verified admin
+// credential or rater token, otherwise by client IP; identities taken from
+// headers are never trusted unverified, so rotating them cannot buy fresh
+// buckets. Verified admin claims are kept on the request so AuthMiddleware
+// does not check the credential again. raterTokens may be nil. It must be
+// installed with Router.Use so the matched route is known.
+func RateLimit(limiter *ratelimit.Limiter, adminAuth *auth.AdminAuthenticator, raterTokens *auth.Keyring) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routeKey(r)
+			limit := limiter.LimitFor(route)
+			if !limit.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client, r := rateLimitClient(r, adminAuth, raterTokens)
+			result := limiter.Allow(r.Context(), route+"|"+client, limit)
+			w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Period)))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				respondError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func routeKey(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return r.Method + " " + template
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// rateLimitClient names the client a request is charged to: the subject of
+// a valid rater token or admin credential, else the client IP. A verified
+// admin credential's claims are put on the returned request.
+func rateLimitClient(r *http.Request, adminAuth *auth.AdminAuthenticator, raterTokens *auth.Keyring) (string, *http.Request) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		if raterTokens != nil && !strings.HasPrefix(token, auth.APIKeyPrefix) {
+			if claims, err := raterTokens.Verify(token); err == nil {
+				return "rater:" + claims.Subject, r
+			}
+		}
+		if claims, err := adminAuth.Authenticate(r.Context(), token); err == nil {
+			return "admin:" + claims.Subject, r.WithContext(context.WithValue(r.Context(), claimsKey, claims))
+		}
+	}
+	return "ip:" + clientIP(r), r
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"robin-camp/internal/auth"
+)
+
+type contextKey string
+
+const (
+	raterIDKey contextKey = "raterID"
+	claimsKey  contextKey = "claims"
+)
+
+// RaterID returns the rater identity established by the rater middleware.
+func RaterID(r *http.Request) string {
+	raterID, _ := r.Context().Value(raterIDKey).(string)
+	return raterID
+}
+
+func withRaterID(r *http.Request, raterID string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), raterIDKey, raterID))
+}
+
+// RaterAuth returns the rater middleware for mode: the X-Rater-Id header as
+// sent, or a signed rater token whose subject is the rater id.
+func RaterAuth(mode string, keyring *auth.Keyring) func(http.Handler) http.Handler {
+	if mode == auth.RaterAuthToken {
+		return RaterTokenMiddleware(keyring)
+	}
+	return RaterIDMiddleware
+}
+
+// RaterTokenMiddleware requires "Authorization: Bearer <rater token>".
+// X-Rater-Id is ignored in this mode.
+func RaterTokenMiddleware(keyring *auth.Keyring) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Missing rater token")
+				return
+			}
+
+			claims, err := keyring.Verify(token)
+			if err != nil {
+				if err.Error() == "token expired" {
+					respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Rater token expired")
+					return
+				}
+				respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid rater token")
+				return
+			}
+
+			next.ServeHTTP(w, withRaterID(r, claims.Subject))
+		})
+	}
+}
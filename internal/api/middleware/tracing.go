@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"robin-camp/internal/logging"
+	"robin-camp/internal/tracing"
+)
+
+// Tracing starts a server span per request, continuing the trace in an
+// incoming traceparent header, and tags the request logger with the trace
+// id. Spans are named by route template. It must be installed with
+// Router.Use after RequestIDMiddleware.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tracing.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if parent, ok := tracing.ParseTraceparent(r.Header.Get("traceparent")); ok {
+			ctx = tracing.ContextWithRemoteParent(ctx, parent)
+		}
+		route := routeKey(r)
+		ctx, span := tracing.Start(ctx, route, tracing.KindServer,
+			tracing.String("http.method", r.Method),
+			tracing.String("http.route", route[len(r.Method)+1:]),
+			tracing.String("http.target", r.URL.RequestURI()),
+			tracing.String("request.id", RequestID(r)),
+		)
+		defer span.End()
+
+		traceID := span.SpanContext().TraceID.String()
+		ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("trace_id", traceID))
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		span.SetAttributes(tracing.Int("http.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetError(errorStatus(rec.status))
+		}
+	})
+}
+
+type errorStatus int
+
+func (s errorStatus) Error() string {
+	return http.StatusText(int(s))
+}
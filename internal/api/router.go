@@ -1,8 +1,14 @@
 package api
 
 import (
+	"net/http"
+	"net/netip"
+
 	"robin-camp/internal/api/handlers"
 	"robin-camp/internal/api/middleware"
+	"robin-camp/internal/auth"
+	"robin-camp/internal/httpconf"
+	"robin-camp/internal/metrics"
 
 	"github.com/gorilla/mux"
 )
@@ -10,33 +16,110 @@ import (
 func SetupRouter(
 	movieHandler *handlers.MovieHandler,
 	ratingHandler *handlers.RatingHandler,
+	trendingHandler *handlers.TrendingHandler,
+	recommendationHandler *handlers.RecommendationHandler,
+	reviewHandler *handlers.ReviewHandler,
+	anomalyHandler *handlers.AnomalyHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	auditHandler *handlers.AuditHandler,
+	raterTokenHandler *handlers.RaterTokenHandler,
 	healthHandler *handlers.HealthHandler,
-	authToken string,
+	adminAuth *auth.AdminAuthenticator,
+	raterAuthMode string,
+	raterTokens *auth.Keyring,
+	trustedProxies []netip.Prefix,
+	rateLimit func(http.Handler) http.Handler,
+	cors httpconf.CORSPolicy,
+	bodyLimit func(http.Handler) http.Handler,
+	compress func(http.Handler) http.Handler,
 ) *mux.Router {
 	r := mux.NewRouter()
 
 	// Apply middleware globally
-	r.Use(middleware.CORS)
+	r.Use(middleware.RequestIDMiddleware)
+	r.Use(middleware.ClientIP(trustedProxies))
+	r.Use(middleware.Tracing)
 	r.Use(middleware.Logger)
+	r.Use(middleware.Metrics)
+	r.Use(middleware.CORS(cors, r))
+	r.Use(rateLimit)
+	r.Use(bodyLimit)
+	r.Use(compress)
+
+	// Protected routes declare the scope they need; authentication runs first
+	authenticate := middleware.AuthMiddleware(adminAuth)
+	requireScope := func(scope string, handler http.HandlerFunc) http.Handler {
+		return authenticate(middleware.RequireScope(scope)(handler))
+	}
+
+	// OPTIONS on any path: CORS preflights are answered by the CORS
+	// middleware, anything else gets the Allow header. Registered first so
+	// it wins over the method-specific routes below.
+	r.Methods(http.MethodOptions).Handler(middleware.Options(r))
 
 	// Health check (no auth)
 	r.HandleFunc("/healthz", healthHandler.HealthCheck).Methods("GET")
 
+	// Prometheus metrics (no auth)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
 	// Movies endpoints
 	r.HandleFunc("/movies", movieHandler.ListMovies).Methods("GET")
+	r.HandleFunc("/movies/top", ratingHandler.TopRated).Methods("GET")
+	r.HandleFunc("/movies/trending", trendingHandler.GetTrending).Methods("GET")
+
+	r.Handle("/movies", requireScope(auth.ScopeMoviesWrite, movieHandler.CreateMovie)).Methods("POST")
+
+	// A movie is addressed by id, or by slug or exact title; {title} below
+	// accepts either of the latter. Fixed paths above take precedence, which
+	// is why slugs never take their names.
+	r.HandleFunc("/movies/id/{id}", movieHandler.GetMovieByID).Methods("GET")
+	r.HandleFunc("/movies/{slug}", movieHandler.GetMovie).Methods("GET")
 
-	// Create movie requires auth
-	createMovieRouter := r.PathPrefix("/movies").Subrouter()
-	createMovieRouter.Use(middleware.AuthMiddleware(authToken))
-	createMovieRouter.HandleFunc("", movieHandler.CreateMovie).Methods("POST")
+	r.HandleFunc("/movies/{title}/similar", movieHandler.SimilarMovies).Methods("GET")
 
 	// Ratings endpoints
 	r.HandleFunc("/movies/{title}/rating", ratingHandler.GetRatingAggregate).Methods("GET")
+	r.HandleFunc("/movies/{title}/reviews", ratingHandler.ListReviews).Methods("GET")
 
-	// Submit rating requires X-Rater-Id
+	// Rater endpoints require X-Rater-Id or, in token mode, a rater token
+	raterAuth := middleware.RaterAuth(raterAuthMode, raterTokens)
+
+	// Submit rating requires a rater identity
 	submitRatingRouter := r.PathPrefix("/movies/{title}/ratings").Subrouter()
-	submitRatingRouter.Use(middleware.RaterIDMiddleware)
+	submitRatingRouter.Use(raterAuth)
 	submitRatingRouter.HandleFunc("", ratingHandler.SubmitRating).Methods("POST")
+	submitRatingRouter.HandleFunc("", ratingHandler.DeleteRating).Methods("DELETE")
+
+	// Review feedback requires a rater identity
+	reviewFeedbackRouter := r.PathPrefix("/movies/{title}/reviews/{raterId}").Subrouter()
+	reviewFeedbackRouter.Use(raterAuth)
+	reviewFeedbackRouter.HandleFunc("/votes", reviewHandler.VoteReview).Methods("POST")
+	reviewFeedbackRouter.HandleFunc("/reports", reviewHandler.ReportReview).Methods("POST")
+
+	// Rater tokens are only issued when a signing key is configured
+	if raterTokenHandler != nil {
+		r.HandleFunc("/raters/token", raterTokenHandler.IssueToken).Methods("POST")
+	}
+
+	// Rater profile endpoints
+	r.HandleFunc("/raters/{raterId}/ratings", ratingHandler.ListRaterRatings).Methods("GET")
+	r.HandleFunc("/raters/{raterId}/recommendations", recommendationHandler.GetRecommendations).Methods("GET")
+
+	// Admin endpoints
+	adminRouter := r.PathPrefix("/admin").Subrouter()
+	adminRouter.Handle("/reviews", requireScope(auth.ScopeAdminRead, reviewHandler.ListModerationQueue)).Methods("GET")
+	adminRouter.Handle("/reviews/reported", requireScope(auth.ScopeAdminRead, reviewHandler.ListReportedReviews)).Methods("GET")
+	adminRouter.Handle("/movies/{title}/reviews/{raterId}/approve", requireScope(auth.ScopeRatingsModerate, reviewHandler.ApproveReview)).Methods("POST")
+	adminRouter.Handle("/movies/{title}/reviews/{raterId}/reject", requireScope(auth.ScopeRatingsModerate, reviewHandler.RejectReview)).Methods("POST")
+	adminRouter.Handle("/movies/{title}/reviews/{raterId}/moderation", requireScope(auth.ScopeAdminRead, reviewHandler.GetModerationHistory)).Methods("GET")
+	adminRouter.Handle("/anomalies", requireScope(auth.ScopeAdminRead, anomalyHandler.ListAnomalies)).Methods("GET")
+	adminRouter.Handle("/anomalies/{id}", requireScope(auth.ScopeAdminRead, anomalyHandler.GetAnomaly)).Methods("GET")
+	adminRouter.Handle("/anomalies/{id}/release", requireScope(auth.ScopeRatingsModerate, anomalyHandler.ReleaseAnomaly)).Methods("POST")
+	adminRouter.Handle("/api-keys", requireScope(auth.ScopeAdminRead, apiKeyHandler.ListKeys)).Methods("GET")
+	adminRouter.Handle("/api-keys", requireScope(auth.ScopeAdminKeys, apiKeyHandler.CreateKey)).Methods("POST")
+	adminRouter.Handle("/api-keys/{id}", requireScope(auth.ScopeAdminKeys, apiKeyHandler.RevokeKey)).Methods("DELETE")
+	adminRouter.Handle("/audit", requireScope(auth.ScopeAdminRead, auditHandler.ListAudit)).Methods("GET")
 
 	return r
 }
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// VerificationKey is a public key (or HMAC secret) accepted for admin JWTs.
+type VerificationKey struct {
+	kid    string
+	alg    string
+	secret []byte
+	rsa    *rsa.PublicKey
+	ecdsa  *ecdsa.PublicKey
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// LoadJWKS reads RSA (RS256), P-256 EC (ES256) and symmetric (HS256) keys
+// from a JSON Web Key Set file. Keys meant for encryption are skipped.
+func LoadJWKS(path string) ([]VerificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	var keys []VerificationKey
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (%q): %w", i, k.Kid, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s has no signing keys", path)
+	}
+	return keys, nil
+}
+
+func parseJWK(k jwk) (VerificationKey, error) {
+	key := VerificationKey{kid: k.Kid}
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return key, fmt.Errorf("invalid modulus")
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return key, fmt.Errorf("invalid exponent")
+		}
+		if n.BitLen() < 2048 {
+			return key, fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		key.alg, key.rsa = AlgRS256, &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		if k.Crv != "P-256" {
+			return key, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return key, fmt.Errorf("invalid x coordinate")
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return key, fmt.Errorf("invalid y coordinate")
+		}
+		curve := elliptic.P256()
+		if !curve.IsOnCurve(x, y) {
+			return key, fmt.Errorf("point is not on curve")
+		}
+		key.alg, key.ecdsa = AlgES256, &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) < 32 {
+			return key, fmt.Errorf("symmetric keys must be at least 32 bytes")
+		}
+		key.alg, key.secret = AlgHS256, secret
+	default:
+		return key, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	if k.Alg != "" && k.Alg != key.alg {
+		return key, fmt.Errorf("unsupported algorithm %q for key type %s", k.Alg, k.Kty)
+	}
+	return key, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
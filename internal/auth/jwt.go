@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Asymmetric algorithms accepted for admin JWTs.
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// Claims are the verified claims of an admin token, kept on the request
+// context. Extra holds every JWT claim, registered or not.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt int64
+	Scopes    []string
+	Extra     map[string]interface{}
+}
+
+// JWTVerifier validates admin JWTs signed with HS256, RS256 or ES256. An
+// empty issuer or audience skips that check.
+type JWTVerifier struct {
+	keys     []VerificationKey
+	issuer   string
+	audience string
+}
+
+// NewJWTVerifier accepts tokens signed with hmacSecret (HS256, if set) or
+// with any of the JWKS keys.
+func NewJWTVerifier(hmacSecret string, jwks []VerificationKey, issuer, audience string) (*JWTVerifier, error) {
+	v := &JWTVerifier{issuer: issuer, audience: audience}
+	if hmacSecret != "" {
+		if len(hmacSecret) < 32 {
+			return nil, fmt.Errorf("HS256 secret must be at least 32 bytes")
+		}
+		v.keys = append(v.keys, VerificationKey{alg: AlgHS256, secret: []byte(hmacSecret)})
+	}
+	v.keys = append(v.keys, jwks...)
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("no JWT verification keys configured")
+	}
+	return v, nil
+}
+
+// Verify checks the signature and the exp, nbf, iss and aud claims of token.
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range v.keys {
+		if key.alg != header.Alg || (header.Kid != "" && key.kid != "" && key.kid != header.Kid) {
+			continue
+		}
+		if verifySignature(key, signingInput, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	var raw map[string]interface{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	claims, err := parseClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if now.Add(-leeway).Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("token expired")
+	}
+	if nbf, ok := numericClaim(raw, "nbf"); ok && now.Add(leeway).Unix() < nbf {
+		return nil, fmt.Errorf("token not yet valid")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("invalid issuer")
+	}
+	if v.audience != "" && !contains(claims.Audience, v.audience) {
+		return nil, fmt.Errorf("invalid audience")
+	}
+
+	return claims, nil
+}
+
+func verifySignature(key VerificationKey, signingInput, signature []byte) bool {
+	digest := sha256.Sum256(signingInput)
+	switch key.alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write(signingInput)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case AlgRS256:
+		return rsa.VerifyPKCS1v15(key.rsa, crypto.SHA256, digest[:], signature) == nil
+	case AlgES256:
+		// JWS encodes ECDSA signatures as fixed-width r || s
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.ecdsa, digest[:], r, s)
+	}
+	return false
+}
+
+func parseClaims(raw map[string]interface{}) (*Claims, error) {
+	claims := &Claims{Extra: raw}
+	if sub, ok := raw["sub"]; ok {
+		s, isString := sub.(string)
+		if !isString {
+			return nil, fmt.Errorf("invalid token")
+		}
+		claims.Subject = s
+	}
+	if iss, ok := raw["iss"].(string); ok {
+		claims.Issuer = iss
+	}
+	switch aud := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+	if exp, ok := numericClaim(raw, "exp"); ok {
+		claims.ExpiresAt = exp
+	}
+
+	// Scopes come as a space-separated "scope" string (RFC 8693) or an
+	// "scp" array
+	if scope, ok := raw["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+	if scp, ok := raw["scp"].([]interface{}); ok {
+		for _, s := range scp {
+			if scope, ok := s.(string); ok {
+				claims.Scopes = append(claims.Scopes, scope)
+			}
+		}
+	}
+	return claims, nil
+}
+
+func numericClaim(raw map[string]interface{}, name string) (int64, bool) {
+	value, ok := raw[name].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int64(value), true
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyVerifier checks database-backed API keys.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*Claims, error)
+}
+
+// APIKeyPrefix starts every API key, telling them apart from JWTs and the
+// static token.
+const APIKeyPrefix = "rck_"
+
+// AdminAuthenticator accepts admin bearer tokens: API keys, JWTs when a
+// verifier is configured, and the static AUTH_TOKEN when it is set.
+type AdminAuthenticator struct {
+	staticToken string
+	verifier    *JWTVerifier
+	apiKeys     APIKeyVerifier
+}
+
+func NewAdminAuthenticator(staticToken string, verifier *JWTVerifier, apiKeys APIKeyVerifier) *AdminAuthenticator {
+	return &AdminAuthenticator{staticToken: staticToken, verifier: verifier, apiKeys: apiKeys}
+}
+
+// StaticSubject is the subject given to requests using the static token,
+// which carries no identity of its own.
+const StaticSubject = "admin"
+
+// Authenticate returns the claims of a valid admin token.
+func (a *AdminAuthenticator) Authenticate(ctx context.Context, token string) (*Claims, error) {
+	if a.staticToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.staticToken)) == 1 {
+		return &Claims{Subject: StaticSubject, Scopes: AllScopes}, nil
+	}
+	if a.apiKeys != nil && strings.HasPrefix(token, APIKeyPrefix) {
+		return a.apiKeys.VerifyAPIKey(ctx, token)
+	}
+	if a.verifier != nil && strings.Count(token, ".") == 2 {
+		return a.verifier.Verify(token)
+	}
+	return nil, fmt.Errorf("invalid token")
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Signing algorithms, named as in the JWT "alg" header.
+const (
+	AlgHS256 = "HS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key is one entry of a keyring. HMAC keys and Ed25519 private keys can
+// both sign and verify; Ed25519 public keys only verify, for tokens minted
+// by an upstream identity service.
+type Key struct {
+	ID  string
+	Alg string
+
+	secret  []byte
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+// CanSign reports whether the key holds signing material.
+func (k *Key) CanSign() bool {
+	return k.secret != nil || k.private != nil
+}
+
+// ParseKeys reads a comma-separated list of "kid=type:base64" entries,
+// where type is hs256, ed25519 (a 32-byte seed or 64-byte private key) or
+// ed25519-public.
+func ParseKeys(spec string) ([]*Key, error) {
+	var keys []*Key
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, rest, ok := strings.Cut(entry, "=")
+		keyType, encoded, ok2 := strings.Cut(rest, ":")
+		if !ok || !ok2 || kid == "" {
+			return nil, fmt.Errorf("invalid key entry %q: want kid=type:base64", entry)
+		}
+		if seen[kid] {
+			return nil, fmt.Errorf("duplicate key id %q", kid)
+		}
+		seen[kid] = true
+
+		material, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kid, err)
+		}
+
+		key := &Key{ID: kid}
+		switch strings.ToLower(keyType) {
+		case "hs256":
+			if len(material) < 32 {
+				return nil, fmt.Errorf("key %q: HMAC secret must be at least 32 bytes", kid)
+			}
+			key.Alg, key.secret = AlgHS256, material
+		case "ed25519":
+			switch len(material) {
+			case ed25519.SeedSize:
+				key.private = ed25519.NewKeyFromSeed(material)
+			case ed25519.PrivateKeySize:
+				key.private = ed25519.PrivateKey(material)
+			default:
+				return nil, fmt.Errorf("key %q: Ed25519 private key must be %d or %d bytes", kid, ed25519.SeedSize, ed25519.PrivateKeySize)
+			}
+			key.Alg, key.public = AlgEdDSA, key.private.Public().(ed25519.PublicKey)
+		case "ed25519-public":
+			if len(material) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("key %q: Ed25519 public key must be %d bytes", kid, ed25519.PublicKeySize)
+			}
+			key.Alg, key.public = AlgEdDSA, ed25519.PublicKey(material)
+		default:
+			return nil, fmt.Errorf("key %q: unknown key type %q", kid, keyType)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// decodeKey accepts standard or URL-safe base64, padded or not.
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimRight(strings.TrimSpace(encoded), "=")
+	if material, err := base64.RawStdEncoding.DecodeString(encoded); err == nil {
+		return material, nil
+	}
+	material, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 key material")
+	}
+	return material, nil
+}
@@ -0,0 +1,31 @@
+package auth
+
+// Scopes grant access to groups of admin endpoints.
+const (
+	ScopeMoviesWrite     = "movies:write"
+	ScopeMoviesDelete    = "movies:delete"
+	ScopeRatingsModerate = "ratings:moderate"
+	ScopeRatersIssue     = "raters:issue"
+	ScopeAdminRead       = "admin:read"
+	ScopeAdminKeys       = "admin:keys"
+)
+
+// AllScopes lists every known scope. The static AUTH_TOKEN holds all of them.
+var AllScopes = []string{
+	ScopeMoviesWrite,
+	ScopeMoviesDelete,
+	ScopeRatingsModerate,
+	ScopeRatersIssue,
+	ScopeAdminRead,
+	ScopeAdminKeys,
+}
+
+// IsScope reports whether s is a known scope.
+func IsScope(s string) bool {
+	return contains(AllScopes, s)
+}
+
+// HasScope reports whether the claims grant scope.
+func (c *Claims) HasScope(scope string) bool {
+	return contains(c.Scopes, scope)
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// leeway absorbs clock skew between this service and token issuers.
+const leeway = 30 * time.Second
+
+// Rater authentication modes: the X-Rater-Id header as sent, or a signed
+// rater token.
+const (
+	RaterAuthHeader = "header"
+	RaterAuthToken  = "token"
+)
+
+// RaterClaims is the payload of a rater token. The subject is the rater id.
+type RaterClaims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid"`
+}
+
+// Keyring issues and verifies rater tokens. Tokens use the JWT compact
+// serialization, so upstream services can mint them with any JWT library.
+// Every key verifies tokens naming its kid; new tokens are signed with the
+// signing key. To rotate, add a new key, make it the signing key, and
+// remove the old one once the tokens it signed have expired.
+type Keyring struct {
+	keys    map[string]*Key
+	signing *Key
+	ttl     time.Duration
+}
+
+// NewKeyring builds a keyring. An empty signingKeyID picks the first key
+// that can sign; a keyring without one can only verify.
+func NewKeyring(keys []*Key, signingKeyID string, ttl time.Duration) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no rater token keys configured")
+	}
+
+	k := &Keyring{keys: make(map[string]*Key), ttl: ttl}
+	for _, key := range keys {
+		k.keys[key.ID] = key
+		if k.signing == nil && signingKeyID == "" && key.CanSign() {
+			k.signing = key
+		}
+	}
+
+	if signingKeyID != "" {
+		key, ok := k.keys[signingKeyID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q is not configured", signingKeyID)
+		}
+		if !key.CanSign() {
+			return nil, fmt.Errorf("signing key %q is a public key", signingKeyID)
+		}
+		k.signing = key
+	}
+
+	return k, nil
+}
+
+// CanIssue reports whether the keyring holds a signing key.
+func (k *Keyring) CanIssue() bool {
+	return k.signing != nil
+}
+
+// Issue signs a token for subject that expires after the keyring's TTL.
+func (k *Keyring) Issue(subject string) (string, time.Time, error) {
+	if k.signing == nil {
+		return "", time.Time{}, fmt.Errorf("no signing key configured")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(k.ttl)
+	header, err := json.Marshal(tokenHeader{Alg: k.signing.Alg, Typ: "JWT", Kid: k.signing.ID})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode token header: %w", err)
+	}
+	claims, err := json.Marshal(RaterClaims{Subject: subject, IssuedAt: now.Unix(), ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(claims)
+	var signature []byte
+	switch k.signing.Alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.signing.secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case AlgEdDSA:
+		signature = ed25519.Sign(k.signing.private, []byte(signingInput))
+	}
+
+	return signingInput + "." + encodeSegment(signature), expiresAt, nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims.
+func (k *Keyring) Verify(token string) (*RaterClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	key, ok := k.keys[header.Kid]
+	if !ok || key.Alg != header.Alg {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	switch key.Alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, fmt.Errorf("invalid token")
+		}
+	case AlgEdDSA:
+		if !ed25519.Verify(key.public, signingInput, signature) {
+			return nil, fmt.Errorf("invalid token")
+		}
+	}
+
+	var claims RaterClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if claims.Subject == "" || claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if time.Now().Add(-leeway).Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("token expired")
+	}
+
+	return &claims, nil
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
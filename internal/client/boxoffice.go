@@ -1,14 +1,19 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
+	"robin-camp/internal/logging"
+	"robin-camp/internal/metrics"
 	"robin-camp/internal/models"
+	"robin-camp/internal/tracing"
 )
 
 type BoxOfficeClient struct {
@@ -17,17 +22,29 @@ type BoxOfficeClient struct {
 	client  *http.Client
 }
 
-func NewBoxOfficeClient(baseURL, apiKey string) *BoxOfficeClient {
+func NewBoxOfficeClient(baseURL, apiKey string, timeout time.Duration) *BoxOfficeClient {
 	return &BoxOfficeClient{
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: timeout,
 		},
 	}
 }
 
-func (c *BoxOfficeClient) GetBoxOffice(title string) (*models.BoxOfficeResponse, error) {
+// GetBoxOffice looks up the box office record for title. The upstream is
+// asked for the given release year, and a record for a different year (the
+// original instead of a remake, say) is rejected rather than merged.
+func (c *BoxOfficeClient) GetBoxOffice(ctx context.Context, title string, year int) (_ *models.BoxOfficeResponse, err error) {
+	ctx, span := tracing.Start(ctx, "BoxOfficeClient.GetBoxOffice", tracing.KindClient,
+		tracing.String("http.method", "GET"),
+		tracing.String("movie.title", title),
+		tracing.Int("movie.year", year))
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Build URL with query parameter
 	u, err := url.Parse(c.baseURL + "/boxoffice")
 	if err != nil {
@@ -36,10 +53,11 @@ func (c *BoxOfficeClient) GetBoxOffice(title string) (*models.BoxOfficeResponse,
 
 	q := u.Query()
 	q.Set("title", title)
+	q.Set("year", strconv.Itoa(year))
 	u.RawQuery = q.Encode()
 
 	// Create request
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -47,24 +65,50 @@ func (c *BoxOfficeClient) GetBoxOffice(title string) (*models.BoxOfficeResponse,
 	// Add API key header
 	req.Header.Set("X-API-Key", c.apiKey)
 
+	// Propagate the trace to the upstream
+	if sc := span.SpanContext(); sc.IsValid() {
+		req.Header.Set("traceparent", sc.Traceparent())
+	}
+
 	// Execute request
+	start := time.Now()
+	observe := func(outcome string) {
+		metrics.BoxOfficeRequests.Inc(outcome)
+		metrics.BoxOfficeRequestDuration.Observe(time.Since(start).Seconds(), outcome)
+	}
 	resp, err := c.client.Do(req)
 	if err != nil {
+		observe("transport_error")
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(tracing.Int("http.status_code", resp.StatusCode))
+	logging.FromContext(ctx).Debug("box office lookup",
+		"title", title,
+		"year", year,
+		"status", resp.StatusCode,
+		"duration_ms", float64(time.Since(start).Microseconds())/1000,
+	)
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		observe("upstream_error")
 		return nil, fmt.Errorf("upstream returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse response
 	var boxOfficeResp models.BoxOfficeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&boxOfficeResp); err != nil {
+		observe("decode_error")
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	released, err := time.Parse("2006-01-02", boxOfficeResp.ReleaseDate)
+	if err != nil || released.Year() != year {
+		observe("release_mismatch")
+		return nil, fmt.Errorf("upstream record has release date %q, want year %d", boxOfficeResp.ReleaseDate, year)
+	}
+	observe("ok")
 
 	return &boxOfficeResp, nil
 }
@@ -1,31 +1,126 @@
 package config
 
 import (
-	"os"
 	"strconv"
+	"time"
 )
 
+// Config is built by Loader.Load from, in increasing precedence: the defaults in
+// the `default` tags, a config file (keys in the `key` tags), environment
+// variables (`env` tags) and command-line flags (the key with dots and
+// underscores replaced by dashes, e.g. --rate-limit-routes). Fields tagged
+// `secret` are redacted by Print; fields tagged `reload` are re-read on
+// SIGHUP.
 type Config struct {
-	Port            string
-	AuthToken       string
-	DatabaseURL     string
-	BoxOfficeURL    string
-	BoxOfficeAPIKey string
-}
+	Port            string `key:"server.port" env:"PORT" default:"8080"`
+	AuthToken       string `key:"auth.token" env:"AUTH_TOKEN" secret:"true"`
+	DatabaseURL     string `key:"database.url" env:"DB_URL" secret:"true"`
+	BoxOfficeURL    string `key:"box_office.url" env:"BOXOFFICE_URL"`
+	BoxOfficeAPIKey string `key:"box_office.api_key" env:"BOXOFFICE_API_KEY" secret:"true"`
 
-func Load() *Config {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	// Admin JWT verification; AUTH_TOKEN stays accepted as a static token
+	AdminJWTSecret   string `key:"auth.jwt_secret" env:"ADMIN_JWT_SECRET" secret:"true"`
+	AdminJWKSFile    string `key:"auth.jwks_file" env:"ADMIN_JWKS_FILE"`
+	AdminJWTIssuer   string `key:"auth.jwt_issuer" env:"ADMIN_JWT_ISSUER"`
+	AdminJWTAudience string `key:"auth.jwt_audience" env:"ADMIN_JWT_AUDIENCE"`
 
-	return &Config{
-		Port:            port,
-		AuthToken:       os.Getenv("AUTH_TOKEN"),
-		DatabaseURL:     os.Getenv("DB_URL"),
-		BoxOfficeURL:    os.Getenv("BOXOFFICE_URL"),
-		BoxOfficeAPIKey: os.Getenv("BOXOFFICE_API_KEY"),
-	}
+	// HTTP server timeouts
+	ReadHeaderTimeout time.Duration `key:"server.read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	ReadTimeout       time.Duration `key:"server.read_timeout" env:"SERVER_READ_TIMEOUT" default:"15s"`
+	WriteTimeout      time.Duration `key:"server.write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `key:"server.idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"60s"`
+
+	// Database connection pools, sized alike for the primary and the
+	// replica. The optional read replica serves movie listing and lookup
+	// and rating aggregates; writes and their existence checks stay on the
+	// primary.
+	DatabaseReplicaURL string        `key:"database.replica_url" env:"DB_REPLICA_URL" secret:"true"`
+	DBMaxOpenConns     int           `key:"database.max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"20"`
+	DBMaxIdleConns     int           `key:"database.max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10"`
+	DBConnMaxLifetime  time.Duration `key:"database.conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	DBConnMaxIdleTime  time.Duration `key:"database.conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+
+	BoxOfficeTimeout time.Duration `key:"box_office.timeout" env:"BOXOFFICE_TIMEOUT" default:"10s"`
+
+	// Bayesian weighting for ratings: each movie is treated as having
+	// RatingMinVotes extra votes at RatingPriorMean.
+	RatingPriorMean float64 `key:"ratings.prior_mean" env:"RATING_PRIOR_MEAN" default:"3.0"`
+	RatingMinVotes  int     `key:"ratings.min_votes" env:"RATING_MIN_VOTES" default:"10"`
+	TopMinCount     int     `key:"ratings.top_min_count" env:"TOP_RATED_MIN_COUNT" default:"5"`
+
+	TrendingRefreshInterval time.Duration `key:"trending.refresh_interval" env:"TRENDING_REFRESH_INTERVAL" default:"1m"`
+
+	// Item-item collaborative filtering
+	RecommendationsEnabled    bool          `key:"recommendations.enabled" env:"RECOMMENDATIONS_ENABLED" default:"true"`
+	RecommendationInterval    time.Duration `key:"recommendations.refresh_interval" env:"RECOMMENDATION_REFRESH_INTERVAL" default:"1h"`
+	RecommendationNeighbors   int           `key:"recommendations.neighbors" env:"RECOMMENDATION_NEIGHBORS" default:"20"`
+	RecommendationMinCoRaters int           `key:"recommendations.min_co_raters" env:"RECOMMENDATION_MIN_CO_RATERS" default:"2"`
+
+	// Weights of the signals blended by the similar-movies endpoint
+	SimilarWeightGenre       float64 `key:"similar.weight_genre" env:"SIMILAR_WEIGHT_GENRE" default:"0.3"`
+	SimilarWeightDistributor float64 `key:"similar.weight_distributor" env:"SIMILAR_WEIGHT_DISTRIBUTOR" default:"0.1"`
+	SimilarWeightEra         float64 `key:"similar.weight_era" env:"SIMILAR_WEIGHT_ERA" default:"0.1"`
+	SimilarWeightMPARating   float64 `key:"similar.weight_mpa_rating" env:"SIMILAR_WEIGHT_MPA_RATING" default:"0.05"`
+	SimilarWeightBudgetTier  float64 `key:"similar.weight_budget_tier" env:"SIMILAR_WEIGHT_BUDGET_TIER" default:"0.05"`
+	SimilarWeightCoRating    float64 `key:"similar.weight_co_rating" env:"SIMILAR_WEIGHT_CO_RATING" default:"0.4"`
+
+	// Review moderation
+	ModerationAutoApprove     bool   `key:"moderation.auto_approve" env:"MODERATION_AUTO_APPROVE" default:"true"`
+	ModerationBlocklistFile   string `key:"moderation.blocklist_file" env:"MODERATION_BLOCKLIST_FILE" default:"./moderation-blocklist.txt"`
+	ModerationMaxLinks        int    `key:"moderation.max_links" env:"MODERATION_MAX_LINKS" default:"1"`
+	ModerationReportThreshold int    `key:"moderation.report_threshold" env:"MODERATION_REPORT_THRESHOLD" default:"5"`
+
+	// Rating anomaly detection
+	AnomalyDetectionEnabled bool          `key:"anomaly.enabled" env:"ANOMALY_DETECTION_ENABLED" default:"true"`
+	AnomalyScanInterval     time.Duration `key:"anomaly.scan_interval" env:"ANOMALY_SCAN_INTERVAL" default:"5m"`
+	AnomalyLookback         time.Duration `key:"anomaly.lookback" env:"ANOMALY_LOOKBACK" default:"24h"`
+	AnomalyBurstWindow      time.Duration `key:"anomaly.burst_window" env:"ANOMALY_BURST_WINDOW" default:"1h"`
+	AnomalyBurstMinRaters   int           `key:"anomaly.burst_min_raters" env:"ANOMALY_BURST_MIN_RATERS" default:"10"`
+	AnomalyExtremeMinRaters int           `key:"anomaly.extreme_min_raters" env:"ANOMALY_EXTREME_MIN_RATERS" default:"3"`
+
+	// Rater identity: "header" trusts X-Rater-Id, "token" requires a signed
+	// rater token
+	RaterAuthMode        string        `key:"rater_auth.mode" env:"RATER_AUTH_MODE" default:"header"`
+	RaterTokenKeys       string        `key:"rater_auth.token_keys" env:"RATER_TOKEN_KEYS" secret:"true"`
+	RaterTokenSigningKey string        `key:"rater_auth.signing_key" env:"RATER_TOKEN_SIGNING_KEY"`
+	RaterTokenTTL        time.Duration `key:"rater_auth.token_ttl" env:"RATER_TOKEN_TTL" default:"24h"`
+
+	// Rate limiting: limits are "<requests>/<period>", empty or "off" to
+	// disable; routes are "<METHOD> <path template>=<limit>,..."
+	TrustedProxies   string `key:"server.trusted_proxies" env:"TRUSTED_PROXIES"`
+	RateLimitStore   string `key:"rate_limit.store" env:"RATE_LIMIT_STORE" default:"memory"`
+	RateLimitDefault string `key:"rate_limit.default" env:"RATE_LIMIT_DEFAULT" reload:"true"`
+	RateLimitRoutes  string `key:"rate_limit.routes" env:"RATE_LIMIT_ROUTES" default:"GET /movies=120/1m,POST /movies/{title}/ratings=30/1m" reload:"true"`
+
+	// CORS: comma-separated origins such as "https://app.example.com",
+	// "https://*.example.com" or "*"; credentialed origins also receive
+	// Access-Control-Allow-Credentials
+	CORSAllowedOrigins      string        `key:"cors.allowed_origins" env:"CORS_ALLOWED_ORIGINS" default:"http://localhost:5173"`
+	CORSCredentialedOrigins string        `key:"cors.credentialed_origins" env:"CORS_CREDENTIALED_ORIGINS"`
+	CORSMaxAge              time.Duration `key:"cors.max_age" env:"CORS_MAX_AGE" default:"1h"`
+
+	// Request bodies: sizes are bytes or "<n>KB"/"<n>MB"; routes are
+	// "<METHOD> <path template>=<size>,..."
+	RequestMaxBodySize     string `key:"request.max_body_size" env:"REQUEST_MAX_BODY_SIZE" default:"1MB"`
+	RequestBodyLimitRoutes string `key:"request.body_limit_routes" env:"REQUEST_BODY_LIMIT_ROUTES" default:"POST /movies=64KB,POST /movies/{title}/ratings=32KB"`
+
+	// Response compression for bodies of at least min_size bytes
+	CompressionEnabled bool `key:"compression.enabled" env:"COMPRESSION_ENABLED" default:"true"`
+	CompressionMinSize int  `key:"compression.min_size" env:"COMPRESSION_MIN_SIZE" default:"1024"`
+
+	// Logging: level is debug, info, warn or error; format is json or text
+	LogLevel  string `key:"log.level" env:"LOG_LEVEL" default:"info" reload:"true"`
+	LogFormat string `key:"log.format" env:"LOG_FORMAT" default:"json"`
+
+	// Tracing: exporter is none, stdout or otlp; the sample ratio applies
+	// to traces started here, incoming traceparent decisions are kept
+	TracingExporter     string  `key:"tracing.exporter" env:"TRACING_EXPORTER" default:"none"`
+	TracingOTLPEndpoint string  `key:"tracing.otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" default:"http://localhost:4318"`
+	TracingServiceName  string  `key:"tracing.service_name" env:"TRACING_SERVICE_NAME" default:"movies-api"`
+	TracingSampleRatio  float64 `key:"tracing.sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1.0" reload:"true"`
+
+	// sources records which layer each key was set by
+	sources map[string]Source
 }
 
 func (c *Config) GetPort() int {
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readFile reads a config file into "section.key" values. Only the subset
+// of YAML and TOML needed for flat sections of scalars is understood: no
+// lists, inline tables, anchors or multi-line strings.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		values, err = parseYAML(string(data))
+	case ".toml":
+		values, err = parseTOML(string(data))
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension, want .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parseYAML reads nested block mappings of scalars, joining the keys of
+// each level with dots.
+func parseYAML(data string) (map[string]string, error) {
+	type level struct {
+		indent int
+		key    string
+	}
+	values := make(map[string]string)
+	var stack []level
+
+	for n, line := range strings.Split(data, "\n") {
+		n++
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			return nil, fmt.Errorf("line %d: lists are not supported", n)
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if strings.HasPrefix(line[indent:], "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", n)
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", n)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", n)
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		path := make([]string, 0, len(stack)+1)
+		for _, l := range stack {
+			path = append(path, l.key)
+		}
+		path = append(path, key)
+		full := strings.Join(path, ".")
+
+		if value == "" {
+			// Start of a nested mapping
+			stack = append(stack, level{indent: indent, key: key})
+			continue
+		}
+		scalar, err := yamlScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if _, dup := values[full]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %s", n, full)
+		}
+		values[full] = scalar
+	}
+	return values, nil
+}
+
+func yamlScalar(value string) (string, error) {
+	switch value[0] {
+	case '"':
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted string %s", value)
+		}
+		return s, nil
+	case '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", fmt.Errorf("invalid single-quoted string %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	case '[', '{', '|', '>', '&', '*', '!':
+		return "", fmt.Errorf("unsupported value %s: only scalars are allowed", value)
+	}
+	if value == "~" || value == "null" {
+		return "", nil
+	}
+	return value, nil
+}
+
+// parseTOML reads [table] headers and key = value pairs of strings,
+// numbers and booleans.
+func parseTOML(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	table := ""
+
+	for n, line := range strings.Split(data, "\n") {
+		n++
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			if strings.HasPrefix(trimmed, "[[") || !strings.HasSuffix(trimmed, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header %s", n, trimmed)
+			}
+			table = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if table == "" {
+				return nil, fmt.Errorf("line %d: empty table name", n)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", n)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", n)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+
+		scalar, err := tomlScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %s", n, key)
+		}
+		values[key] = scalar
+	}
+	return values, nil
+}
+
+func tomlScalar(value string) (string, error) {
+	switch value[0] {
+	case '"':
+		if strings.HasPrefix(value, `"""`) {
+			return "", fmt.Errorf("multi-line strings are not supported")
+		}
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", value)
+		}
+		return s, nil
+	case '\'':
+		if strings.HasPrefix(value, "'''") {
+			return "", fmt.Errorf("multi-line strings are not supported")
+		}
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", fmt.Errorf("invalid literal string %s", value)
+		}
+		return value[1 : len(value)-1], nil
+	case '[', '{':
+		return "", fmt.Errorf("unsupported value %s: only scalars are allowed", value)
+	}
+	// Numbers may use underscores as separators
+	return strings.ReplaceAll(value, "_", ""), nil
+}
+
+// stripComment drops a "#" comment that is not inside a quoted string.
+// YAML and TOML both require whitespace or line start before the "#".
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source names the layer a setting's value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// field describes one tagged Config field.
+type field struct {
+	index    int
+	key      string
+	env      string
+	def      string
+	secret   bool
+	reload   bool
+	flagName string
+}
+
+var fields = configFields()
+
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	var fs []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("key")
+		if key == "" {
+			continue
+		}
+		fs = append(fs, field{
+			index:    i,
+			key:      key,
+			env:      sf.Tag.Get("env"),
+			def:      sf.Tag.Get("default"),
+			secret:   sf.Tag.Get("secret") == "true",
+			reload:   sf.Tag.Get("reload") == "true",
+			flagName: strings.NewReplacer(".", "-", "_", "-").Replace(key),
+		})
+	}
+	return fs
+}
+
+// Loader builds a Config from defaults, a config file, the environment and
+// command-line flags, each layer overriding the one before.
+type Loader struct {
+	flags       *flag.FlagSet
+	file        *string
+	printConfig *bool
+	values      map[string]*string
+}
+
+// NewLoader registers a flag for every setting, plus --config and
+// --print-config, on fs. Call Load after fs has been parsed.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		flags:       fs,
+		file:        fs.String("config", "", "config file (.yaml, .yml or .toml); overrides CONFIG_FILE"),
+		printConfig: fs.Bool("print-config", false, "print the effective configuration with secrets redacted and exit"),
+		values:      make(map[string]*string),
+	}
+	for _, f := range fields {
+		l.values[f.key] = fs.String(f.flagName, "", fmt.Sprintf("%s; overrides %s", f.key, f.env))
+	}
+	return l
+}
+
+// PrintConfig reports whether --print-config was given.
+func (l *Loader) PrintConfig() bool {
+	return *l.printConfig
+}
+
+// Load reads every layer and validates the result. On validation failure
+// the Config is still returned, alongside a *ValidationError listing every
+// problem, so it can be printed; other errors return a nil Config.
+func (l *Loader) Load() (*Config, error) {
+	raw := make(map[string]string, len(fields))
+	sources := make(map[string]Source, len(fields))
+	for _, f := range fields {
+		raw[f.key] = f.def
+		sources[f.key] = SourceDefault
+	}
+
+	var problems []string
+
+	path := *l.file
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := values[key]
+			if _, ok := raw[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown key in %s", key, path))
+				continue
+			}
+			raw[key] = value
+			sources[key] = SourceFile
+		}
+	}
+
+	// A variable set to the empty string still overrides, so that it can
+	// clear a value from the config file
+	for _, f := range fields {
+		if value, ok := os.LookupEnv(f.env); ok {
+			raw[f.key] = value
+			sources[f.key] = SourceEnv
+		}
+	}
+
+	l.flags.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if f.flagName == fl.Name {
+				raw[f.key] = *l.values[f.key]
+				sources[f.key] = SourceFlag
+			}
+		}
+	})
+
+	cfg := &Config{sources: sources}
+	v := reflect.ValueOf(cfg).Elem()
+	invalid := make(map[string]bool)
+	for _, f := range fields {
+		if err := setField(v.Field(f.index), raw[f.key]); err != nil {
+			problems = append(problems, f.problem(err.Error()))
+			invalid[f.key] = true
+		}
+	}
+
+	// A value that failed to parse is left zero; don't report it twice
+	for _, problem := range cfg.validate() {
+		key, _, _ := strings.Cut(problem, " ")
+		if !invalid[key] {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+func setField(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(s)
+	case time.Duration:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		v.SetInt(int64(d))
+	case int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(int64(n))
+	case float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(n)
+	case bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (f field) problem(msg string) string {
+	return fmt.Sprintf("%s (%s): %s", f.key, f.env, msg)
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Changed compares two configurations and returns the keys of changed
+// settings, split by whether they can be applied without a restart.
+func Changed(prev, next *Config) (reloadable, restart []string) {
+	ov := reflect.ValueOf(prev).Elem()
+	nv := reflect.ValueOf(next).Elem()
+	for _, f := range fields {
+		if ov.Field(f.index).Interface() == nv.Field(f.index).Interface() {
+			continue
+		}
+		if f.reload {
+			reloadable = append(reloadable, f.key)
+		} else {
+			restart = append(restart, f.key)
+		}
+	}
+	return reloadable, restart
+}
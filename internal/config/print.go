@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "<redacted>"
+
+// Print writes c as a YAML config file, grouped by section, with each
+// value's source as a comment. Secrets that are set print as <redacted>.
+func (c *Config) Print(w io.Writer) error {
+	var sections []string
+	bySection := make(map[string][]field)
+	for _, f := range fields {
+		section, _, _ := strings.Cut(f.key, ".")
+		if _, ok := bySection[section]; !ok {
+			sections = append(sections, section)
+		}
+		bySection[section] = append(bySection[section], f)
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	var b strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:\n", section)
+		for _, f := range bySection[section] {
+			_, name, _ := strings.Cut(f.key, ".")
+			value := formatValue(v.Field(f.index).Interface())
+			if f.secret && value != `""` {
+				value = strconv.Quote(redacted)
+			}
+			source := c.sources[f.key]
+			if source == "" {
+				source = SourceDefault
+			}
+			fmt.Fprintf(&b, "  %s: %s # %s, %s\n", name, value, source, f.env)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case time.Duration:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
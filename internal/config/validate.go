@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"robin-camp/internal/auth"
+	"robin-camp/internal/httpconf"
+	"robin-camp/internal/ratelimit"
+)
+
+// validate returns every problem with c, each prefixed with the key and
+// environment variable to fix.
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(key, format string, args ...any) {
+		problems = append(problems, fieldByKey(key).problem(fmt.Sprintf(format, args...)))
+	}
+
+	if port := c.GetPort(); port < 1 || port > 65535 || fmt.Sprint(port) != c.Port {
+		add("server.port", "must be a port number between 1 and 65535, got %q", c.Port)
+	}
+	if c.DatabaseURL == "" {
+		add("database.url", "is required")
+	}
+
+	// Admin endpoints need at least one way in
+	if c.AuthToken == "" && c.AdminJWTSecret == "" && c.AdminJWKSFile == "" {
+		add("auth.token", "an admin credential is required: set AUTH_TOKEN, ADMIN_JWT_SECRET or ADMIN_JWKS_FILE")
+	}
+	if strings.ContainsAny(c.AuthToken, " \t\r\n") {
+		add("auth.token", "must not contain whitespace")
+	}
+	if c.AdminJWTSecret != "" && len(c.AdminJWTSecret) < 32 {
+		add("auth.jwt_secret", "must be at least 32 bytes")
+	}
+	if c.AdminJWKSFile != "" {
+		if _, err := auth.LoadJWKS(c.AdminJWKSFile); err != nil {
+			add("auth.jwks_file", "%v", err)
+		}
+	}
+
+	if c.BoxOfficeURL != "" {
+		if u, err := url.Parse(c.BoxOfficeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("box_office.url", "must be an http or https URL, got %q", c.BoxOfficeURL)
+		}
+	}
+
+	if c.DBMaxOpenConns < 0 {
+		add("database.max_open_conns", "must not be negative (0 means unlimited)")
+	}
+	if c.DBMaxIdleConns < 0 {
+		add("database.max_idle_conns", "must not be negative")
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		add("database.max_idle_conns", "must not exceed max_open_conns (%d)", c.DBMaxOpenConns)
+	}
+
+	// Every duration is an interval or timeout and must be positive
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range fields {
+		if d, ok := v.Field(f.index).Interface().(time.Duration); ok && d <= 0 {
+			add(f.key, "must be a positive duration")
+		}
+	}
+
+	if c.RatingPriorMean < 0.5 || c.RatingPriorMean > 5 {
+		add("ratings.prior_mean", "must be between 0.5 and 5")
+	}
+	for _, key := range []string{
+		"ratings.min_votes", "ratings.top_min_count", "moderation.max_links",
+		"recommendations.neighbors", "recommendations.min_co_raters",
+		"moderation.report_threshold", "anomaly.burst_min_raters", "anomaly.extreme_min_raters",
+	} {
+		if v.Field(fieldByKey(key).index).Int() < 0 {
+			add(key, "must not be negative")
+		}
+	}
+	for _, key := range []string{
+		"similar.weight_genre", "similar.weight_distributor", "similar.weight_era",
+		"similar.weight_mpa_rating", "similar.weight_budget_tier", "similar.weight_co_rating",
+	} {
+		if v.Field(fieldByKey(key).index).Float() < 0 {
+			add(key, "must not be negative")
+		}
+	}
+	if _, err := os.Stat(c.ModerationBlocklistFile); err != nil {
+		add("moderation.blocklist_file", "%v", err)
+	}
+
+	if c.RaterTokenKeys != "" {
+		keys, err := auth.ParseKeys(c.RaterTokenKeys)
+		if err != nil {
+			add("rater_auth.token_keys", "%v", err)
+		} else if _, err := auth.NewKeyring(keys, c.RaterTokenSigningKey, c.RaterTokenTTL); err != nil {
+			add("rater_auth.signing_key", "%v", err)
+		}
+	}
+	switch c.RaterAuthMode {
+	case auth.RaterAuthHeader:
+	case auth.RaterAuthToken:
+		if c.RaterTokenKeys == "" {
+			add("rater_auth.token_keys", "is required when rater_auth.mode is token")
+		}
+	default:
+		add("rater_auth.mode", "must be header or token, got %q", c.RaterAuthMode)
+	}
+
+	if _, err := httpconf.ParseTrustedProxies(c.TrustedProxies); err != nil {
+		add("server.trusted_proxies", "%v", err)
+	}
+	if c.RateLimitStore != "memory" && c.RateLimitStore != "postgres" {
+		add("rate_limit.store", "must be memory or postgres, got %q", c.RateLimitStore)
+	}
+	if _, err := ratelimit.ParseLimit(c.RateLimitDefault); err != nil {
+		add("rate_limit.default", "%v", err)
+	}
+	if _, err := ratelimit.ParseRoutes(c.RateLimitRoutes); err != nil {
+		add("rate_limit.routes", "%v", err)
+	}
+
+	if _, err := httpconf.ParseOrigins(c.CORSAllowedOrigins); err != nil {
+		add("cors.allowed_origins", "%v", err)
+	}
+	if _, err := httpconf.ParseCORSPolicy("", c.CORSCredentialedOrigins, c.CORSMaxAge); err != nil {
+		add("cors.credentialed_origins", "%v", err)
+	}
+
+	if _, err := httpconf.ParseByteSize(c.RequestMaxBodySize); err != nil {
+		add("request.max_body_size", "%v", err)
+	}
+	if _, err := httpconf.ParseBodyLimits(c.RequestBodyLimitRoutes); err != nil {
+		add("request.body_limit_routes", "%v", err)
+	}
+	if c.CompressionMinSize < 0 {
+		add("compression.min_size", "must not be negative")
+	}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		add("log.level", "must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	switch strings.ToLower(c.LogFormat) {
+	case "json", "text":
+	default:
+		add("log.format", "must be json or text, got %q", c.LogFormat)
+	}
+
+	switch c.TracingExporter {
+	case "none", "stdout":
+	case "otlp":
+		if u, err := url.Parse(c.TracingOTLPEndpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			add("tracing.otlp_endpoint", "must be a URL, got %q", c.TracingOTLPEndpoint)
+		}
+	default:
+		add("tracing.exporter", "must be none, stdout or otlp, got %q", c.TracingExporter)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		add("tracing.sample_ratio", "must be between 0 and 1")
+	}
+
+	return problems
+}
+
+func fieldByKey(key string) field {
+	for _, f := range fields {
+		if f.key == key {
+			return f
+		}
+	}
+	panic("config: unknown key " + key)
+}
@@ -8,17 +8,36 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+
+	"robin-camp/internal/tracing"
 )
 
-func Connect(dbURL string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", dbURL)
+// PoolOptions sizes a connection pool. MaxOpenConns of 0 means no limit
+// and MaxIdleConns of 0 keeps no idle connections.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func Connect(dbURL string, pool PoolOptions) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dbURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	// Statements run with a traced context get a span each
+	db := sql.OpenDB(tracing.WrapConnector(connector))
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
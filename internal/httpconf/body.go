@@ -0,0 +1,51 @@
+package httpconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseByteSize parses a size such as "512", "64KB" or "1MB" (binary
+// multiples) into bytes.
+func ParseByteSize(s string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, multiplier = strings.TrimSpace(rest), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q: want a positive number of bytes, KB or MB", s)
+	}
+	return n * multiplier, nil
+}
+
+// ParseBodyLimits parses comma-separated "<METHOD> <path template>=<size>"
+// entries, e.g. "POST /movies=64KB,POST /movies/{title}/ratings=16KB".
+func ParseBodyLimits(s string) (map[string]int64, error) {
+	limits := make(map[string]int64)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, size, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || method == "" || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+			return nil, fmt.Errorf("invalid body limit %q: want \"<METHOD> <path>=<size>\"", entry)
+		}
+		n, err := ParseByteSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body limit %q: %w", entry, err)
+		}
+		limits[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = n
+	}
+	return limits, nil
+}
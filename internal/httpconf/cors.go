@@ -0,0 +1,121 @@
+// Package httpconf parses the HTTP settings shared by config validation and
+// the server middleware: CORS origins, trusted proxies and body size limits.
+package httpconf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OriginPattern matches the Origin header of cross-origin requests: "*"
+// matches any origin, "https://app.example.com" matches exactly and
+// "https://*.example.com" matches any subdomain of example.com but not
+// example.com itself.
+type OriginPattern struct {
+	any    bool
+	scheme string
+	// host is the exact host, or for wildcards the suffix including the
+	// leading dot
+	host     string
+	port     string
+	wildcard bool
+}
+
+// ParseOrigins parses a comma-separated list of origin patterns.
+func ParseOrigins(spec string) ([]OriginPattern, error) {
+	var patterns []OriginPattern
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			patterns = append(patterns, OriginPattern{any: true})
+			continue
+		}
+
+		u, err := url.Parse(entry)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return nil, fmt.Errorf("invalid origin %q: want scheme://host[:port]", entry)
+		}
+		p := OriginPattern{scheme: u.Scheme, host: strings.ToLower(u.Hostname()), port: u.Port()}
+		if rest, ok := strings.CutPrefix(p.host, "*."); ok {
+			if rest == "" || strings.Contains(rest, "*") {
+				return nil, fmt.Errorf("invalid origin %q: wildcard must be a leading \"*.\" label", entry)
+			}
+			p.host = "." + rest
+			p.wildcard = true
+		} else if strings.Contains(p.host, "*") {
+			return nil, fmt.Errorf("invalid origin %q: wildcard must be a leading \"*.\" label", entry)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Matches reports whether origin is covered by the pattern.
+func (p OriginPattern) Matches(origin *url.URL) bool {
+	if p.any {
+		return true
+	}
+	if origin.Scheme != p.scheme || origin.Port() != p.port {
+		return false
+	}
+	host := strings.ToLower(origin.Hostname())
+	if p.wildcard {
+		return strings.HasSuffix(host, p.host) && len(host) > len(p.host)
+	}
+	return host == p.host
+}
+
+// CORSPolicy decides which origins may call the API from a browser.
+// Credentialed origins are also allowed, and additionally receive
+// Access-Control-Allow-Credentials so browsers send cookies and HTTP auth.
+type CORSPolicy struct {
+	AllowedOrigins      []OriginPattern
+	CredentialedOrigins []OriginPattern
+	MaxAge              time.Duration
+}
+
+// ParseCORSPolicy builds a policy from comma-separated origin lists.
+// Credentialed origins must be explicit: "*" is rejected there, because
+// browsers refuse credentials on wildcard responses.
+func ParseCORSPolicy(allowed, credentialed string, maxAge time.Duration) (CORSPolicy, error) {
+	allowedOrigins, err := ParseOrigins(allowed)
+	if err != nil {
+		return CORSPolicy{}, err
+	}
+	credentialedOrigins, err := ParseOrigins(credentialed)
+	if err != nil {
+		return CORSPolicy{}, err
+	}
+	for _, p := range credentialedOrigins {
+		if p.any {
+			return CORSPolicy{}, fmt.Errorf("credentialed origins cannot be \"*\"")
+		}
+	}
+	return CORSPolicy{AllowedOrigins: allowedOrigins, CredentialedOrigins: credentialedOrigins, MaxAge: maxAge}, nil
+}
+
+// Check reports whether origin is allowed, whether it gets credentials and
+// whether it may be answered with a literal "*".
+func (p CORSPolicy) Check(origin string) (allowed, credentials, anyOrigin bool) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false, false, false
+	}
+	for _, pattern := range p.CredentialedOrigins {
+		if pattern.Matches(u) {
+			return true, true, false
+		}
+	}
+	for _, pattern := range p.AllowedOrigins {
+		if pattern.Matches(u) {
+			return true, false, pattern.any
+		}
+	}
+	return false, false, false
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+)
+
+// Default holds the application's metrics and is served on /metrics.
+var Default = NewRegistry()
+
+// Application metrics. HTTP routes are labelled by their mux path template,
+// never the raw path, to keep label cardinality bounded.
+var (
+	HTTPRequests = Default.NewCounterVec("http_requests_total",
+		"HTTP requests handled, by method, route template and status code.",
+		"method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency, by method, route template and status code.",
+		DefaultBuckets, "method", "route", "status")
+
+	BoxOfficeRequests = Default.NewCounterVec("boxoffice_requests_total",
+		"Box office API calls, by outcome: ok, upstream_error, transport_error, decode_error or release_mismatch.",
+		"outcome")
+	BoxOfficeRequestDuration = Default.NewHistogramVec("boxoffice_request_duration_seconds",
+		"Box office API call latency, by outcome.",
+		DefaultBuckets, "outcome")
+	BoxOfficeEnrichmentInFlight = Default.NewGauge("boxoffice_enrichment_in_flight",
+		"Movies waiting on box office enrichment. Enrichment currently runs inline in movie creation, so this is the number of creations blocked on the lookup.")
+
+	RatingSubmissions = Default.NewCounterVec("rating_submissions_total",
+		"Accepted rating submissions, by result: new or updated.",
+		"result")
+)
+
+// Handler serves the default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// RegisterDBStats exposes the connection pool statistics of db as metrics
+// named prefix_*, e.g. "db" for the primary and "db_replica" for a replica.
+func RegisterDBStats(db *sql.DB, prefix string) {
+	gauge := func(name, help string, fn func(sql.DBStats) float64) {
+		Default.NewGaugeFunc(prefix+"_"+name, help, func() float64 { return fn(db.Stats()) })
+	}
+	counter := func(name, help string, fn func(sql.DBStats) float64) {
+		Default.NewCounterFunc(prefix+"_"+name, help, func() float64 { return fn(db.Stats()) })
+	}
+
+	gauge("max_open_connections", "Maximum number of open connections to the database.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("open_connections", "Established connections, both in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("in_use_connections", "Connections currently in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("wait_count_total", "Connections waited for because the pool was exhausted.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("wait_duration_seconds_total", "Time spent waiting for a connection.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("max_idle_closed_total", "Connections closed due to the idle connection limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("max_idle_time_closed_total", "Connections closed due to the idle time limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	counter("max_lifetime_closed_total", "Connections closed due to the connection lifetime limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
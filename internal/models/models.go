@@ -2,9 +2,13 @@ package models
 
 import "time"
 
+// RatingScale lists every accepted rating value in ascending order.
+var RatingScale = []float64{0.5, 1.0, 1.5, 2.0, 2.5, 3.0, 3.5, 4.0, 4.5, 5.0}
+
 type Movie struct {
 	ID          string     `json:"id"`
 	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
 	ReleaseDate string     `json:"releaseDate"`
 	Genre       string     `json:"genre"`
 	Distributor *string    `json:"distributor,omitempty"`
@@ -39,25 +43,420 @@ type MoviePage struct {
 	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
+// MovieRef names a movie in a request path: a slug or an exact title,
+// narrowed to a release year when Year is non-zero.
+type MovieRef struct {
+	Ref  string
+	Year int
+}
+
+// MovieChoice is one of the movies an ambiguous title names, listed in the
+// details of a 300 response.
+type MovieChoice struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"releaseDate"`
+	Location    string `json:"location"`
+}
+
 type Rating struct {
 	MovieTitle string  `json:"movieTitle"`
 	RaterID    string  `json:"raterId"`
 	Rating     float64 `json:"rating"`
+	Review     *Review `json:"review,omitempty"`
+	// MovieSlug addresses the resolved movie, e.g. in Location headers
+	MovieSlug string `json:"-"`
 }
 
+// RatingSubmit carries an optional review. Omitting both review fields
+// leaves any existing review untouched; an empty body removes it.
 type RatingSubmit struct {
-	Rating float64 `json:"rating"`
+	Rating   float64 `json:"rating"`
+	Headline *string `json:"headline,omitempty"`
+	Body     *string `json:"body,omitempty"`
+}
+
+const (
+	MaxReviewHeadlineLength = 120
+	MaxReviewBodyLength     = 5000
+)
+
+// ReviewInput is the review part of a rating submission. A nil Body
+// removes the review. Status and ModerationReason carry the content
+// filters' decision.
+type ReviewInput struct {
+	Headline         *string
+	Body             *string
+	Status           string
+	ModerationReason string
+}
+
+type Review struct {
+	RaterID        string    `json:"raterId"`
+	Rating         float64   `json:"rating"`
+	Headline       *string   `json:"headline,omitempty"`
+	Body           string    `json:"body"`
+	HelpfulCount   int       `json:"helpfulCount"`
+	UnhelpfulCount int       `json:"unhelpfulCount"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	Edited         bool      `json:"edited"`
+}
+
+type ModerationDecision struct {
+	Reason string `json:"reason"`
+}
+
+type ModerationEvent struct {
+	Actor      string    `json:"actor"`
+	FromStatus *string   `json:"fromStatus,omitempty"`
+	ToStatus   string    `json:"toStatus"`
+	Reason     *string   `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type ModerationItem struct {
+	MovieID    string `json:"movieId"`
+	MovieTitle string `json:"movieTitle"`
+	Review     Review `json:"review"`
+}
+
+type ModerationQueue struct {
+	Items      []ModerationItem `json:"items"`
+	NextCursor *string          `json:"nextCursor,omitempty"`
+}
+
+type ModerationHistory struct {
+	MovieTitle string            `json:"movieTitle"`
+	ReviewerID string            `json:"reviewerId"`
+	Status     string            `json:"status"`
+	Events     []ModerationEvent `json:"events"`
+}
+
+type ReviewVoteSubmit struct {
+	Vote string `json:"vote"`
+}
+
+type ReviewVote struct {
+	MovieTitle     string `json:"movieTitle"`
+	ReviewerID     string `json:"reviewerId"`
+	VoterID        string `json:"voterId"`
+	Vote           string `json:"vote"`
+	HelpfulCount   int    `json:"helpfulCount"`
+	UnhelpfulCount int    `json:"unhelpfulCount"`
+}
+
+// ReportReasons lists the accepted values of ReviewReportSubmit.Reason.
+var ReportReasons = []string{"spam", "offensive", "spoiler", "off_topic", "other"}
+
+const MaxReportDetailsLength = 500
+
+type ReviewReportSubmit struct {
+	Reason  string  `json:"reason"`
+	Details *string `json:"details,omitempty"`
+}
+
+type ReviewReport struct {
+	MovieTitle string    `json:"movieTitle"`
+	ReviewerID string    `json:"reviewerId"`
+	ReporterID string    `json:"reporterId"`
+	Reason     string    `json:"reason"`
+	Details    *string   `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type ReportedReview struct {
+	MovieID        string         `json:"movieId"`
+	MovieTitle     string         `json:"movieTitle"`
+	Review         Review         `json:"review"`
+	ReportCount    int            `json:"reportCount"`
+	Reasons        map[string]int `json:"reasons"`
+	LastReportedAt time.Time      `json:"lastReportedAt"`
+}
+
+type ReportedReviewPage struct {
+	Items      []ReportedReview `json:"items"`
+	NextCursor *string          `json:"nextCursor,omitempty"`
+}
+
+type ReviewPage struct {
+	MovieTitle string   `json:"movieTitle"`
+	Items      []Review `json:"items"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+// RatingPrior configures the Bayesian weighted rating: a movie's score is
+// pulled towards Mean as if it had MinVotes extra ratings at that value.
+type RatingPrior struct {
+	Mean     float64
+	MinVotes int
 }
 
 type RatingAggregate struct {
-	Average float64 `json:"average"`
+	Average         float64        `json:"average"`
+	WeightedAverage float64        `json:"weightedAverage"`
+	Count           int            `json:"count"`
+	Median          *float64       `json:"median,omitempty"`
+	StdDev          *float64       `json:"stdDev,omitempty"`
+	Distribution    []RatingBucket `json:"distribution,omitempty"`
+}
+
+// RatingStats is the materialized aggregate kept in movie_rating_stats.
+// Buckets holds one count per value of RatingScale.
+type RatingStats struct {
+	MovieID string  `json:"movieId"`
+	Sum     float64 `json:"sum"`
 	Count   int     `json:"count"`
+	Buckets []int64 `json:"buckets"`
+}
+
+type RatingStatsDrift struct {
+	MovieID  string      `json:"movieId"`
+	Expected RatingStats `json:"expected"`
+	Actual   RatingStats `json:"actual"`
+}
+
+type TopMovie struct {
+	Rank            int     `json:"rank"`
+	Movie           Movie   `json:"movie"`
+	Average         float64 `json:"average"`
+	WeightedAverage float64 `json:"weightedAverage"`
+	Count           int     `json:"count"`
+}
+
+type TopMovies struct {
+	Items []TopMovie `json:"items"`
+}
+
+type RatingBucket struct {
+	Rating float64 `json:"rating"`
+	Count  int     `json:"count"`
+}
+
+type RaterRating struct {
+	MovieID    string    `json:"movieId"`
+	MovieTitle string    `json:"movieTitle"`
+	Rating     float64   `json:"rating"`
+	RatedAt    time.Time `json:"ratedAt"`
+}
+
+type RaterStats struct {
+	Count        int            `json:"count"`
+	Mean         float64        `json:"mean"`
+	Distribution []RatingBucket `json:"distribution"`
+}
+
+type RaterRatingPage struct {
+	RaterID    string        `json:"raterId"`
+	Items      []RaterRating `json:"items"`
+	NextCursor *string       `json:"nextCursor,omitempty"`
+	Stats      RaterStats    `json:"stats"`
+}
+
+type TrendingMovie struct {
+	Rank        int     `json:"rank"`
+	Movie       Movie   `json:"movie"`
+	Score       float64 `json:"score"`
+	RatingCount int     `json:"ratingCount"`
+	Average     float64 `json:"average"`
+}
+
+type TrendingMovies struct {
+	Window      string          `json:"window"`
+	Weighted    bool            `json:"weighted"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Items       []TrendingMovie `json:"items"`
+}
+
+// RatingEntry is one cell of the movie x rater rating matrix.
+type RatingEntry struct {
+	MovieID string
+	RaterID string
+	Rating  float64
+}
+
+// RecentRating is a rating considered by the anomaly detector. NewRater
+// is set when the rater was first seen inside the detector's lookback;
+// FirstAction when this is the rating the rater was first seen with.
+type RecentRating struct {
+	ID          int64
+	MovieID     string
+	RaterID     string
+	Rating      float64
+	CreatedAt   time.Time
+	NewRater    bool
+	FirstAction bool
+}
+
+// AnomalyThresholds tunes the rating anomaly detector.
+type AnomalyThresholds struct {
+	Lookback         time.Duration
+	BurstWindow      time.Duration
+	BurstMinRaters   int
+	ExtremeMinRaters int
+}
+
+// AnomalyCandidate is a group of ratings the detector wants quarantined.
+type AnomalyCandidate struct {
+	MovieID   string
+	Kind      string
+	Detail    string
+	RatingIDs []int64
+}
+
+type RatingAnomaly struct {
+	ID          int64           `json:"id"`
+	MovieID     string          `json:"movieId"`
+	MovieTitle  string          `json:"movieTitle"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	RatingCount int             `json:"ratingCount"`
+	Detail      string          `json:"detail"`
+	DetectedAt  time.Time       `json:"detectedAt"`
+	ReleasedAt  *time.Time      `json:"releasedAt,omitempty"`
+	ReleasedBy  *string         `json:"releasedBy,omitempty"`
+	Ratings     []AnomalyRating `json:"ratings,omitempty"`
+}
+
+type AnomalyRating struct {
+	RaterID     string    `json:"raterId"`
+	Rating      float64   `json:"rating"`
+	RatedAt     time.Time `json:"ratedAt"`
+	Quarantined bool      `json:"quarantined"`
+}
+
+type RatingAnomalyPage struct {
+	Items      []RatingAnomaly `json:"items"`
+	NextCursor *string         `json:"nextCursor,omitempty"`
+}
+
+type MovieNeighbor struct {
+	MovieID    string
+	NeighborID string
+	Similarity float64
+	CoRaters   int
+}
+
+type Recommendation struct {
+	Movie           Movie   `json:"movie"`
+	PredictedRating float64 `json:"predictedRating"`
+	BasedOn         int     `json:"basedOn"`
+}
+
+type RecommendationList struct {
+	RaterID string           `json:"raterId"`
+	Items   []Recommendation `json:"items"`
+}
+
+// SimilarityWeights sets how much each signal contributes to the
+// "similar movies" score.
+type SimilarityWeights struct {
+	Genre       float64
+	Distributor float64
+	Era         float64
+	MPARating   float64
+	BudgetTier  float64
+	CoRating    float64
+}
+
+// SimilarityMatch is a candidate movie with the signals it shares with the
+// target movie; CoRating is the stored item-item similarity, 0 if none.
+type SimilarityMatch struct {
+	Movie           Movie
+	Score           float64
+	SameGenre       bool
+	SameDistributor bool
+	SameEra         bool
+	SameMPARating   bool
+	SameBudgetTier  bool
+	CoRating        float64
+}
+
+type SimilarMovie struct {
+	Movie       Movie    `json:"movie"`
+	Score       float64  `json:"score"`
+	Explanation []string `json:"explanation"`
+}
+
+type SimilarMovies struct {
+	Title string         `json:"title"`
+	Items []SimilarMovie `json:"items"`
+}
+
+// Actor identifies who made an administrative change and from where.
+type Actor struct {
+	ID        string
+	RequestID string
+	ClientIP  string
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditEntry struct {
+	ID        int64                  `json:"id"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	Target    string                 `json:"target"`
+	Changes   map[string]AuditChange `json:"changes"`
+	RequestID *string                `json:"requestId,omitempty"`
+	ClientIP  *string                `json:"clientIp,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type AuditPage struct {
+	Items      []AuditEntry `json:"items"`
+	NextCursor *string      `json:"nextCursor,omitempty"`
+}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+type APIKeyCreate struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// APIKeyCreated carries the plaintext key, which is only ever shown once.
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyList struct {
+	Items []APIKey `json:"items"`
+}
+
+type RaterTokenRequest struct {
+	RaterID string `json:"raterId"`
+}
+
+type RaterToken struct {
+	RaterID   string    `json:"raterId"`
+	Token     string    `json:"token"`
+	TokenType string    `json:"tokenType"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type Error struct {
-	Code    string      `json:"code"`
-	Message string      `json:"message"`
-	Details interface{} `json:"details,omitempty"`
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
 }
 
 type BoxOfficeResponse struct {
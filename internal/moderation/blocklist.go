@@ -0,0 +1,72 @@
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// BlocklistFilter rejects text containing any blocked word or phrase.
+// Matching is case-insensitive and on whole words.
+type BlocklistFilter struct {
+	terms []string
+}
+
+// LoadBlocklist reads one term per line from path. Blank lines and lines
+// starting with "#" are ignored.
+func LoadBlocklist(path string) (*BlocklistFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist: %w", err)
+	}
+	defer file.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, normalize(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+
+	return &BlocklistFilter{terms: terms}, nil
+}
+
+func (f *BlocklistFilter) Name() string {
+	return "blocklist"
+}
+
+func (f *BlocklistFilter) Check(text string) Result {
+	// Pad with spaces so every term match falls on word boundaries
+	padded := " " + normalize(text) + " "
+	for _, term := range f.terms {
+		if strings.Contains(padded, " "+term+" ") {
+			return Result{Filter: f.Name(), Verdict: Reject, Reason: "contains blocked term"}
+		}
+	}
+	return Result{Filter: f.Name(), Verdict: Allow}
+}
+
+// normalize lowercases text and collapses every run of non-alphanumeric
+// characters into a single space.
+func normalize(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
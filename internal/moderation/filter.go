@@ -0,0 +1,78 @@
+package moderation
+
+import "strings"
+
+// Verdict is a content filter's decision on a piece of text.
+type Verdict int
+
+const (
+	// Allow means the filter found nothing wrong.
+	Allow Verdict = iota
+	// Flag holds the text for manual review.
+	Flag
+	// Reject refuses the text outright.
+	Reject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Flag:
+		return "flag"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// Result is the outcome of running one filter.
+type Result struct {
+	Filter  string
+	Verdict Verdict
+	Reason  string
+}
+
+// ContentFilter inspects user-submitted text.
+type ContentFilter interface {
+	Name() string
+	Check(text string) Result
+}
+
+// Pipeline runs every filter over a text and combines their verdicts.
+type Pipeline struct {
+	filters     []ContentFilter
+	autoApprove bool
+}
+
+// NewPipeline builds a pipeline. With autoApprove set, text that every
+// filter allows is approved straight away; otherwise it waits for review.
+func NewPipeline(autoApprove bool, filters ...ContentFilter) *Pipeline {
+	return &Pipeline{filters: filters, autoApprove: autoApprove}
+}
+
+// Evaluate returns the initial review status for text and the reason for
+// it, if any filter objected. The strictest verdict wins.
+func (p *Pipeline) Evaluate(text string) (string, string) {
+	worst := Allow
+	var reasons []string
+	for _, f := range p.filters {
+		result := f.Check(text)
+		if result.Verdict == Allow {
+			continue
+		}
+		if result.Verdict > worst {
+			worst = result.Verdict
+		}
+		reasons = append(reasons, result.Filter+": "+result.Reason)
+	}
+
+	reason := strings.Join(reasons, "; ")
+	switch {
+	case worst == Reject:
+		return StatusRejected, reason
+	case worst == Flag || !p.autoApprove:
+		return StatusPending, reason
+	default:
+		return StatusApproved, reason
+	}
+}
@@ -0,0 +1,66 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// SpamFilter flags text that looks like link spam or shouting.
+type SpamFilter struct {
+	maxLinks int
+}
+
+func NewSpamFilter(maxLinks int) *SpamFilter {
+	return &SpamFilter{maxLinks: maxLinks}
+}
+
+func (f *SpamFilter) Name() string {
+	return "spam"
+}
+
+func (f *SpamFilter) Check(text string) Result {
+	if links := len(linkPattern.FindAllString(text, -1)); links > f.maxLinks {
+		return Result{Filter: f.Name(), Verdict: Flag, Reason: fmt.Sprintf("contains %d links", links)}
+	}
+
+	if repeatedRun(text) >= 10 {
+		return Result{Filter: f.Name(), Verdict: Flag, Reason: "repeated characters"}
+	}
+
+	var letters, upper int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters >= 20 && upper*10 >= letters*7 {
+		return Result{Filter: f.Name(), Verdict: Flag, Reason: "mostly upper case"}
+	}
+
+	return Result{Filter: f.Name(), Verdict: Allow}
+}
+
+// repeatedRun returns the length of the longest run of one repeated
+// non-space character.
+func repeatedRun(text string) int {
+	longest, run := 0, 0
+	var prev rune
+	for _, r := range text {
+		if r == prev && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
@@ -0,0 +1,51 @@
+package moderation
+
+// Review moderation states. Only approved reviews are shown publicly.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusHidden   = "hidden"
+)
+
+// transitions lists the states each state may move to. Hidden is only
+// entered automatically from approved when reports pile up, and pending is
+// only re-entered when the author edits the review (see EditStatus).
+var transitions = map[string][]string{
+	StatusPending:  {StatusApproved, StatusRejected},
+	StatusApproved: {StatusRejected, StatusHidden, StatusPending},
+	StatusHidden:   {StatusApproved, StatusRejected, StatusPending},
+	StatusRejected: {StatusApproved, StatusPending},
+}
+
+// EditStatus returns the status of a review after its author rewrites it,
+// given its status before the edit and the status the content filters gave
+// the new text. Text the filters reject is rejected; an approved review
+// stays approved only if the filters would approve the new text; any other
+// edit, in particular of a rejected or hidden review, waits for review.
+func EditStatus(previous, evaluated string) string {
+	switch {
+	case evaluated == StatusRejected:
+		return StatusRejected
+	case previous == StatusApproved && evaluated == StatusApproved:
+		return StatusApproved
+	default:
+		return StatusPending
+	}
+}
+
+// CanTransition reports whether a review may move from one state to another.
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStatus reports whether s is a known moderation state.
+func IsStatus(s string) bool {
+	_, ok := transitions[s]
+	return ok
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"robin-camp/internal/logging"
+)
+
+// sweepInterval is how often buckets that have refilled completely, and so
+// carry no state, are dropped.
+const sweepInterval = time.Minute
+
+// Store keeps buckets by key. Implementations must make Take atomic per key.
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+	// Sweep deletes buckets that are full, which behave exactly like
+	// missing ones.
+	Sweep() error
+}
+
+// Policy maps routes, keyed by "<METHOD> <path template>", to limits;
+// routes without an entry use Default.
+type Policy struct {
+	Routes  map[string]Limit
+	Default Limit
+}
+
+// Limiter applies limits to keys using a Store.
+type Limiter struct {
+	store  Store
+	policy atomic.Pointer[Policy]
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	l := &Limiter{store: store}
+	l.SetPolicy(policy)
+	return l
+}
+
+// SetPolicy replaces the limits applied to routes. Existing buckets are
+// kept and refill at the new rate.
+func (l *Limiter) SetPolicy(policy Policy) {
+	l.policy.Store(&policy)
+}
+
+// LimitFor returns the limit applied to route.
+func (l *Limiter) LimitFor(route string) Limit {
+	policy := l.policy.Load()
+	if limit, ok := policy.Routes[route]; ok {
+		return limit
+	}
+	return policy.Default
+}
+
+// Allow takes a request from key's bucket. It fails open: if the store is
+// unavailable the request is allowed and the error is logged.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) Result {
+	result, err := l.store.Take(key, limit)
+	if err != nil {
+		logging.FromContext(ctx).Error("rate limit store failed, allowing request", "error", err)
+		return Result{Allowed: true, Limit: limit.Requests, Remaining: limit.Requests}
+	}
+	return result
+}
+
+// Start sweeps full buckets on an interval until ctx is cancelled.
+func (l *Limiter) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.store.Sweep(); err != nil {
+					slog.Error("failed to sweep rate limit buckets", "error", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so that all
+// replicas share them. Time is taken from the database clock.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(key string, limit Limit) (Result, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Create the bucket full if needed, then lock it
+	_, err = tx.Exec(`
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at)
+		VALUES ($1, $2, now(), now())
+		ON CONFLICT (key) DO NOTHING
+	`, key, limit.Requests)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var tokens float64
+	var updatedAt, now time.Time
+	err = tx.QueryRow(`
+		SELECT tokens, updated_at, now()
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE
+	`, key).Scan(&tokens, &updatedAt, &now)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to lock rate limit bucket: %w", err)
+	}
+
+	tokens, result := take(tokens, now.Sub(updatedAt), limit)
+
+	_, err = tx.Exec(`
+		UPDATE rate_limit_buckets
+		SET tokens = $2, updated_at = $3, full_at = $4
+		WHERE key = $1
+	`, key, tokens, now, now.Add(result.Reset))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("failed to commit rate limit bucket: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *PostgresStore) Sweep() error {
+	_, err := s.db.Exec(`DELETE FROM rate_limit_buckets WHERE full_at <= now()`)
+	if err != nil {
+		return fmt.Errorf("failed to sweep rate limit buckets: %w", err)
+	}
+	return nil
+}
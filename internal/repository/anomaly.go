@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"robin-camp/internal/models"
+)
+
+// ListRecentRatings returns the ratings made within lookback that have
+// never been part of an anomaly, ordered by movie and time. Each rating
+// notes whether its rater is new and whether it is their first action,
+// both judged from the raters table since ratings.created_at moves on
+// every upsert.
+func (r *RatingRepository) ListRecentRatings(lookback time.Duration) ([]models.RecentRating, error) {
+	query := `
+		WITH recent AS (
+			SELECT id, movie_id, rater_id, rating, created_at
+			FROM ratings
+			WHERE created_at >= CURRENT_TIMESTAMP - make_interval(secs => $1)
+			  AND anomaly_id IS NULL
+		)
+		SELECT recent.id, recent.movie_id, recent.rater_id, recent.rating, recent.created_at,
+		       seen.first_seen_at >= CURRENT_TIMESTAMP - make_interval(secs => $1),
+		       recent.id = seen.first_rating_id
+		FROM recent
+		JOIN raters seen ON seen.rater_id = recent.rater_id
+		ORDER BY recent.movie_id, recent.created_at, recent.id
+	`
+
+	rows, err := r.db.Query(query, lookback.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []models.RecentRating
+	for rows.Next() {
+		var rating models.RecentRating
+		err := rows.Scan(
+			&rating.ID, &rating.MovieID, &rating.RaterID, &rating.Rating, &rating.CreatedAt,
+			&rating.NewRater, &rating.FirstAction,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list recent ratings: %w", err)
+	}
+
+	return ratings, nil
+}
+
+// QuarantineRatings records an anomaly and takes its ratings out of
+// movie_rating_stats without deleting them. Ratings changed or already
+// flagged since detection are skipped; if none remain, nothing is recorded
+// and nil is returned.
+func (r *RatingRepository) QuarantineRatings(candidate models.AnomalyCandidate) (*models.RatingAnomaly, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := lockStats(tx, candidate.MovieID)
+	if err != nil {
+		return nil, err
+	}
+
+	anomaly := &models.RatingAnomaly{
+		MovieID: candidate.MovieID,
+		Kind:    candidate.Kind,
+		Status:  "quarantined",
+		Detail:  candidate.Detail,
+	}
+	err = tx.QueryRow(`
+		INSERT INTO rating_anomalies (movie_id, kind, rating_count, detail)
+		VALUES ($1, $2, 0, $3)
+		RETURNING id, detected_at
+	`, candidate.MovieID, candidate.Kind, candidate.Detail).Scan(&anomaly.ID, &anomaly.DetectedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anomaly: %w", err)
+	}
+
+	rows, err := tx.Query(`
+		UPDATE ratings SET quarantined = true, anomaly_id = $2
+		WHERE id = ANY($1) AND movie_id = $3 AND anomaly_id IS NULL
+		RETURNING rating
+	`, pq.Array(candidate.RatingIDs), anomaly.ID, candidate.MovieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quarantine ratings: %w", err)
+	}
+	for rows.Next() {
+		var rating float64
+		if err := rows.Scan(&rating); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan quarantined rating: %w", err)
+		}
+		applyRating(stats, rating, -1)
+		anomaly.RatingCount++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to quarantine ratings: %w", err)
+	}
+
+	if anomaly.RatingCount == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE rating_anomalies SET rating_count = $2 WHERE id = $1`, anomaly.ID, anomaly.RatingCount); err != nil {
+		return nil, fmt.Errorf("failed to update anomaly: %w", err)
+	}
+	if err := saveStats(tx, stats); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit quarantine: %w", err)
+	}
+
+	return anomaly, nil
+}
+
+// ReleaseAnomaly returns an anomaly's quarantined ratings to
+// movie_rating_stats and marks it released by actor.
+func (r *RatingRepository) ReleaseAnomaly(id int64, actor string) (*models.RatingAnomaly, error) {
+	var movieID string
+	err := r.db.QueryRow(`SELECT movie_id FROM rating_anomalies WHERE id = $1`, id).Scan(&movieID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("anomaly not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomaly: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := lockStats(tx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM rating_anomalies WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("anomaly not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock anomaly: %w", err)
+	}
+	if status != "quarantined" {
+		return nil, fmt.Errorf("anomaly already released")
+	}
+
+	rows, err := tx.Query(`
+		UPDATE ratings SET quarantined = false
+		WHERE anomaly_id = $1 AND quarantined
+		RETURNING rating
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release ratings: %w", err)
+	}
+	for rows.Next() {
+		var rating float64
+		if err := rows.Scan(&rating); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan released rating: %w", err)
+		}
+		applyRating(stats, rating, 1)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to release ratings: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE rating_anomalies
+		SET status = 'released', released_at = CURRENT_TIMESTAMP, released_by = $2
+		WHERE id = $1
+	`, id, actor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release anomaly: %w", err)
+	}
+	if err := saveStats(tx, stats); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit release: %w", err)
+	}
+
+	return r.GetAnomaly(id)
+}
+
+const anomalyColumns = `
+	a.id, a.movie_id, m.title, a.kind, a.status, a.rating_count, a.detail,
+	a.detected_at, a.released_at, a.released_by`
+
+func scanAnomaly(row interface{ Scan(...interface{}) error }, anomaly *models.RatingAnomaly, extra ...interface{}) error {
+	var releasedAt sql.NullTime
+	var releasedBy sql.NullString
+	dest := []interface{}{
+		&anomaly.ID, &anomaly.MovieID, &anomaly.MovieTitle, &anomaly.Kind, &anomaly.Status,
+		&anomaly.RatingCount, &anomaly.Detail, &anomaly.DetectedAt, &releasedAt, &releasedBy,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if releasedAt.Valid {
+		anomaly.ReleasedAt = &releasedAt.Time
+	}
+	if releasedBy.Valid {
+		anomaly.ReleasedBy = &releasedBy.String
+	}
+	return nil
+}
+
+// GetAnomaly returns an anomaly together with the ratings it flagged.
+func (r *RatingRepository) GetAnomaly(id int64) (*models.RatingAnomaly, error) {
+	var anomaly models.RatingAnomaly
+	row := r.db.QueryRow(`
+		SELECT `+anomalyColumns+`
+		FROM rating_anomalies a
+		JOIN movies m ON m.id = a.movie_id
+		WHERE a.id = $1
+	`, id)
+	err := scanAnomaly(row, &anomaly)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("anomaly not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomaly: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT rater_id, rating, created_at, quarantined
+		FROM ratings
+		WHERE anomaly_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomaly ratings: %w", err)
+	}
+	defer rows.Close()
+
+	anomaly.Ratings = []models.AnomalyRating{}
+	for rows.Next() {
+		var rating models.AnomalyRating
+		if err := rows.Scan(&rating.RaterID, &rating.Rating, &rating.RatedAt, &rating.Quarantined); err != nil {
+			return nil, fmt.Errorf("failed to scan anomaly rating: %w", err)
+		}
+		anomaly.Ratings = append(anomaly.Ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get anomaly ratings: %w", err)
+	}
+
+	return &anomaly, nil
+}
+
+// ListAnomalies pages through anomalies in the given status, most recently
+// detected first.
+func (r *RatingRepository) ListAnomalies(status string, limit int, cursor string) ([]models.RatingAnomaly, *string, error) {
+	query := `
+		SELECT ` + anomalyColumns + `, a.detected_at::text
+		FROM rating_anomalies a
+		JOIN movies m ON m.id = a.movie_id
+		WHERE a.status = $1
+	`
+	args := []interface{}{status}
+	argCount := 2
+
+	// Apply cursor
+	if cursor != "" {
+		value, idStr, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || !validCursorValue(value, cursorTimestamp) {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (a.detected_at, a.id) < ($%d::timestamp, $%d)", argCount, argCount+1)
+		args = append(args, value, id)
+		argCount += 2
+	}
+
+	// Order and limit
+	query += " ORDER BY a.detected_at DESC, a.id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []models.RatingAnomaly
+	var sortValues []string
+	for rows.Next() {
+		var anomaly models.RatingAnomaly
+		var sortValue string
+		if err := scanAnomaly(rows, &anomaly, &sortValue); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan anomaly: %w", err)
+		}
+		anomalies = append(anomalies, anomaly)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list anomalies: %w", err)
+	}
+
+	// Determine next cursor
+	var nextCursor *string
+	if limit > 0 && len(anomalies) > limit {
+		anomalies = anomalies[:limit]
+		next := encodeCursor(sortValues[limit-1], strconv.FormatInt(anomalies[limit-1].ID, 10))
+		nextCursor = &next
+	}
+
+	return anomalies, nextCursor, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"robin-camp/internal/models"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, expires_at, last_used_at, created_by, created_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }, key *models.APIKey, extra ...interface{}) error {
+	var scopes pq.StringArray
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	dest := []interface{}{
+		&key.ID, &key.Name, &key.Prefix, &scopes, &expiresAt, &lastUsedAt,
+		&key.CreatedBy, &key.CreatedAt, &revokedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	key.Scopes = []string(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey, secretHash string) error {
+	err := r.db.QueryRow(`
+		INSERT INTO api_keys (name, prefix, secret_hash, scopes, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, key.Name, key.Prefix, secretHash, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedBy).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetByPrefix returns a key that is neither revoked nor expired, together
+// with its secret hash, or nil if there is none.
+func (r *APIKeyRepository) GetByPrefix(prefix string) (*models.APIKey, string, error) {
+	var key models.APIKey
+	var secretHash string
+	row := r.db.QueryRow(`
+		SELECT `+apiKeyColumns+`, secret_hash
+		FROM api_keys
+		WHERE prefix = $1 AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`, prefix)
+	err := scanAPIKey(row, &key, &secretHash)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get api key: %w", err)
+	}
+	return &key, secretHash, nil
+}
+
+// TouchLastUsed records that a key was used, at most once a minute so
+// busy keys do not write on every request.
+func (r *APIKeyRepository) TouchLastUsed(id int64) error {
+	_, err := r.db.Exec(`
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) List() ([]models.APIKey, error) {
+	rows, err := r.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke disables a key and returns it, or nil if there was no active key
+// with that id.
+func (r *APIKeyRepository) Revoke(id int64) (*models.APIKey, error) {
+	var key models.APIKey
+	row := r.db.QueryRow(`
+		UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns, id)
+	err := scanAPIKey(row, &key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return &key, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"robin-camp/internal/models"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Insert(ctx context.Context, entry *models.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO audit_log (actor, action, target, changes, request_id, client_ip)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, entry.Actor, entry.Action, entry.Target, changes, entry.RequestID, entry.ClientIP).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// List pages through the audit log, newest first. Supported filters are
+// actor, target (both exact) and since (a time.Time).
+func (r *AuditRepository) List(filters map[string]interface{}, limit int, cursor string) ([]models.AuditEntry, *string, error) {
+	query := `
+		SELECT id, actor, action, target, changes, request_id, client_ip, created_at, created_at::text
+		FROM audit_log
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 1
+
+	// Apply filters
+	if actor, ok := filters["actor"].(string); ok && actor != "" {
+		query += fmt.Sprintf(" AND actor = $%d", argCount)
+		args = append(args, actor)
+		argCount++
+	}
+
+	if target, ok := filters["target"].(string); ok && target != "" {
+		query += fmt.Sprintf(" AND target = $%d", argCount)
+		args = append(args, target)
+		argCount++
+	}
+
+	if since, ok := filters["since"].(time.Time); ok {
+		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, since)
+		argCount++
+	}
+
+	// Apply cursor
+	if cursor != "" {
+		value, idStr, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || !validCursorValue(value, cursorTimestamp) {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d::timestamp, $%d)", argCount, argCount+1)
+		args = append(args, value, id)
+		argCount += 2
+	}
+
+	// Order and limit
+	query += " ORDER BY created_at DESC, id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	var sortValues []string
+	for rows.Next() {
+		var entry models.AuditEntry
+		var changes []byte
+		var requestID, clientIP sql.NullString
+		var sortValue string
+		err := rows.Scan(
+			&entry.ID, &entry.Actor, &entry.Action, &entry.Target, &changes,
+			&requestID, &clientIP, &entry.CreatedAt, &sortValue,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+		if requestID.Valid {
+			entry.RequestID = &requestID.String
+		}
+		if clientIP.Valid {
+			entry.ClientIP = &clientIP.String
+		}
+		entries = append(entries, entry)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	// Determine next cursor
+	var nextCursor *string
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+		next := encodeCursor(sortValues[limit-1], strconv.FormatInt(entries[limit-1].ID, 10))
+		nextCursor = &next
+	}
+
+	return entries, nextCursor, nil
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
+)
+
+// logModeration appends a status change to the moderation log and returns
+// the recorded event.
+func logModeration(tx *sql.Tx, ratingID int64, actor string, fromStatus *string, toStatus, reason string) (*models.ModerationEvent, error) {
+	event := &models.ModerationEvent{
+		Actor:      actor,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+	}
+	if reason != "" {
+		event.Reason = &reason
+	}
+
+	err := tx.QueryRow(`
+		INSERT INTO review_moderation_log (rating_id, actor, from_status, to_status, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, ratingID, actor, fromStatus, toStatus, event.Reason).Scan(&event.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record moderation event: %w", err)
+	}
+	return event, nil
+}
+
+// ModerateReview moves a review to a new moderation status on behalf of
+// actor and records the change in the moderation log. Transitions not
+// allowed by the state machine fail with "invalid transition".
+func (r *RatingRepository) ModerateReview(movieID, reviewerID, actor, toStatus, reason string) (*models.ModerationEvent, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ratingID, err := lockReview(tx, movieID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromStatus string
+	if err := tx.QueryRow(`SELECT review_status FROM ratings WHERE id = $1`, ratingID).Scan(&fromStatus); err != nil {
+		return nil, fmt.Errorf("failed to get review status: %w", err)
+	}
+	if !moderation.CanTransition(fromStatus, toStatus) {
+		return nil, fmt.Errorf("invalid transition")
+	}
+
+	if _, err := tx.Exec(`UPDATE ratings SET review_status = $2 WHERE id = $1`, ratingID, toStatus); err != nil {
+		return nil, fmt.Errorf("failed to update review status: %w", err)
+	}
+	event, err := logModeration(tx, ratingID, actor, &fromStatus, toStatus, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit moderation: %w", err)
+	}
+
+	return event, nil
+}
+
+// hideIfReported hides an approved review once its report count reaches
+// threshold. A threshold of 0 disables automatic hiding.
+func hideIfReported(tx *sql.Tx, ratingID int64, threshold int) error {
+	if threshold <= 0 {
+		return nil
+	}
+
+	var hidden bool
+	err := tx.QueryRow(`
+		UPDATE ratings SET review_status = $2
+		WHERE id = $1 AND review_status = $3 AND review_report_count >= $4
+		RETURNING true
+	`, ratingID, moderation.StatusHidden, moderation.StatusApproved, threshold).Scan(&hidden)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to hide reported review: %w", err)
+	}
+
+	from := moderation.StatusApproved
+	reason := fmt.Sprintf("report count reached %d", threshold)
+	_, err = logModeration(tx, ratingID, "system", &from, moderation.StatusHidden, reason)
+	return err
+}
+
+// ListModerationQueue pages through reviews in the given status across all
+// movies, oldest first.
+func (r *RatingRepository) ListModerationQueue(status string, limit int, cursor string) ([]models.ModerationItem, *string, error) {
+	query := `
+		SELECT r.id, r.movie_id, m.title, r.rater_id, r.rating, r.review_headline, r.review_body,
+		       r.review_helpful_count, r.review_unhelpful_count, r.review_status,
+		       r.review_created_at, r.review_updated_at, r.review_updated_at::text
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.review_body IS NOT NULL AND r.review_status = $1
+	`
+	args := []interface{}{status}
+	argCount := 2
+
+	// Apply cursor
+	if cursor != "" {
+		value, idStr, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || !validCursorValue(value, cursorTimestamp) {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (r.review_updated_at, r.id) > ($%d::timestamp, $%d)", argCount, argCount+1)
+		args = append(args, value, id)
+		argCount += 2
+	}
+
+	// Order and limit
+	query += " ORDER BY r.review_updated_at ASC, r.id ASC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.ModerationItem
+	var ids []int64
+	var sortValues []string
+	for rows.Next() {
+		var item models.ModerationItem
+		var id int64
+		var headline sql.NullString
+		var sortValue string
+		err := rows.Scan(
+			&id, &item.MovieID, &item.MovieTitle, &item.Review.RaterID, &item.Review.Rating,
+			&headline, &item.Review.Body, &item.Review.HelpfulCount, &item.Review.UnhelpfulCount,
+			&item.Review.Status, &item.Review.CreatedAt, &item.Review.UpdatedAt, &sortValue,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		if headline.Valid {
+			item.Review.Headline = &headline.String
+		}
+		item.Review.Edited = item.Review.UpdatedAt.After(item.Review.CreatedAt)
+
+		items = append(items, item)
+		ids = append(ids, id)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+
+	// Determine next cursor
+	var nextCursor *string
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		next := encodeCursor(sortValues[limit-1], strconv.FormatInt(ids[limit-1], 10))
+		nextCursor = &next
+	}
+
+	return items, nextCursor, nil
+}
+
+// GetModerationHistory returns a review's current status and its moderation
+// log, oldest event first.
+func (r *RatingRepository) GetModerationHistory(movieID, reviewerID string) (string, []models.ModerationEvent, error) {
+	var ratingID int64
+	var status string
+	err := r.db.QueryRow(`
+		SELECT id, review_status FROM ratings
+		WHERE movie_id = $1 AND rater_id = $2 AND review_body IS NOT NULL
+	`, movieID, reviewerID).Scan(&ratingID, &status)
+	if err == sql.ErrNoRows {
+		return "", nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get review: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT actor, from_status, to_status, reason, created_at
+		FROM review_moderation_log
+		WHERE rating_id = $1
+		ORDER BY id ASC
+	`, ratingID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get moderation history: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ModerationEvent{}
+	for rows.Next() {
+		var event models.ModerationEvent
+		var fromStatus, reason sql.NullString
+		if err := rows.Scan(&event.Actor, &fromStatus, &event.ToStatus, &reason, &event.CreatedAt); err != nil {
+			return "", nil, fmt.Errorf("failed to scan moderation event: %w", err)
+		}
+		if fromStatus.Valid {
+			event.FromStatus = &fromStatus.String
+		}
+		if reason.Valid {
+			event.Reason = &reason.String
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to get moderation history: %w", err)
+	}
+
+	return status, events, nil
+}
@@ -1,34 +1,68 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
+
 	"robin-camp/internal/models"
 )
 
 type MovieRepository struct {
 	db *sql.DB
+	// replica serves reads that can tolerate replication lag; it is db when
+	// no replica is configured
+	replica *sql.DB
+}
+
+func NewMovieRepository(db, replica *sql.DB) *MovieRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &MovieRepository{db: db, replica: replica}
 }
 
-func NewMovieRepository(db *sql.DB) *MovieRepository {
-	return &MovieRepository{db: db}
+// slugAttempts bounds how often Create retries after losing a race for a slug.
+const slugAttempts = 3
+
+// Create inserts movie with a slug derived from its title, which it sets on
+// movie. It fails with "movie already exists" when a movie with the same
+// normalized title was released in the same year.
+func (r *MovieRepository) Create(ctx context.Context, movie *models.Movie, boxOffice *models.BoxOffice) error {
+	var err error
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		err = r.create(ctx, movie, boxOffice)
+		if !isUniqueViolation(err, "idx_movies_slug") {
+			return err
+		}
+	}
+	return err
 }
 
-func (r *MovieRepository) Create(movie *models.Movie, boxOffice *models.BoxOffice) error {
-	tx, err := r.db.Begin()
+func (r *MovieRepository) create(ctx context.Context, movie *models.Movie, boxOffice *models.BoxOffice) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	slug, err := nextSlug(ctx, tx, slugBase(movie.Title))
+	if err != nil {
+		return err
+	}
+
 	// Insert movie
 	query := `
-		INSERT INTO movies (id, title, genre, release_date, distributor, budget, mpa_rating)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO movies (id, title, slug, genre, release_date, distributor, budget, mpa_rating)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err = tx.Exec(query, movie.ID, movie.Title, movie.Genre, movie.ReleaseDate,
+	_, err = tx.ExecContext(ctx, query, movie.ID, movie.Title, slug, movie.Genre, movie.ReleaseDate,
 		movie.Distributor, movie.Budget, movie.MPARating)
+	if isUniqueViolation(err, "idx_movies_title_year") {
+		return fmt.Errorf("movie already exists")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert movie: %w", err)
 	}
@@ -39,67 +73,121 @@ func (r *MovieRepository) Create(movie *models.Movie, boxOffice *models.BoxOffic
 			INSERT INTO box_office (movie_id, revenue_worldwide, revenue_opening_weekend_usa, currency, source, last_updated)
 			VALUES ($1, $2, $3, $4, $5, $6)
 		`
-		_, err = tx.Exec(boxOfficeQuery, movie.ID, boxOffice.Revenue.Worldwide,
+		_, err = tx.ExecContext(ctx, boxOfficeQuery, movie.ID, boxOffice.Revenue.Worldwide,
 			boxOffice.Revenue.OpeningWeekendUSA, boxOffice.Currency, boxOffice.Source, boxOffice.LastUpdated)
 		if err != nil {
 			return fmt.Errorf("failed to insert box office data: %w", err)
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	movie.Slug = slug
+	return nil
+}
+
+// FindByRef returns the movies a path reference can name: the movie with
+// that slug and the movies with that exact title, restricted to ref.Year
+// when set, ordered by release date. It reads from the replica and may miss
+// a movie created moments ago; write paths use FindByRefFromPrimary.
+func (r *MovieRepository) FindByRef(ref models.MovieRef) ([]models.Movie, error) {
+	return findMovies(r.replica, movieByRef, ref.Ref, ref.Year)
+}
+
+// FindByRefFromPrimary is FindByRef without replication lag.
+func (r *MovieRepository) FindByRefFromPrimary(ref models.MovieRef) ([]models.Movie, error) {
+	return findMovies(r.db, movieByRef, ref.Ref, ref.Year)
 }
 
-func (r *MovieRepository) GetByTitle(title string) (*models.Movie, error) {
+// GetByID reads a movie by id from the replica.
+func (r *MovieRepository) GetByID(id string) (*models.Movie, error) {
+	movies, err := findMovies(r.replica, movieByID, id)
+	if err != nil || len(movies) == 0 {
+		return nil, err
+	}
+	return &movies[0], nil
+}
+
+// Lookups for findMovies. Without a year, a ref matches the movie with that
+// slug and every movie with that exact title; callers let the slug win. A
+// year restricts the matches to that release year, and then lets a slug
+// stand for its movie's title, so a slug plus a year finds that title's
+// release from the given year.
+const (
+	movieByRef = `($2::int = 0 AND (m.slug = $1 OR m.title = $1))
+		OR ($2::int <> 0 AND EXTRACT(YEAR FROM m.release_date) = $2::int
+			AND (m.title = $1 OR m.title = (SELECT title FROM movies WHERE slug = $1)))`
+	movieByID = "m.id = $1"
+)
+
+func findMovies(db *sql.DB, where string, args ...interface{}) ([]models.Movie, error) {
 	query := `
-		SELECT m.id, m.title, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
+		SELECT m.id, m.title, m.slug, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
 		       b.revenue_worldwide, b.revenue_opening_weekend_usa, b.currency, b.source, b.last_updated
 		FROM movies m
 		LEFT JOIN box_office b ON m.id = b.movie_id
-		WHERE m.title = $1
+		WHERE ` + where + `
+		ORDER BY m.release_date, m.id
 	`
 
-	var movie models.Movie
-	var boxOffice models.BoxOffice
-	var revenueWorldwide sql.NullInt64
-	var revenueOpeningWeekendUSA sql.NullInt64
-	var currency sql.NullString
-	var source sql.NullString
-	var lastUpdated sql.NullTime
-
-	err := r.db.QueryRow(query, title).Scan(
-		&movie.ID, &movie.Title, &movie.Genre, &movie.ReleaseDate,
-		&movie.Distributor, &movie.Budget, &movie.MPARating,
-		&revenueWorldwide, &revenueOpeningWeekendUSA, &currency, &source, &lastUpdated,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movie: %w", err)
 	}
+	defer rows.Close()
+
+	var movies []models.Movie
+	for rows.Next() {
+		var movie models.Movie
+		var boxOffice models.BoxOffice
+		var revenueWorldwide sql.NullInt64
+		var revenueOpeningWeekendUSA sql.NullInt64
+		var currency sql.NullString
+		var source sql.NullString
+		var lastUpdated sql.NullTime
+
+		err := rows.Scan(
+			&movie.ID, &movie.Title, &movie.Slug, &movie.Genre, &movie.ReleaseDate,
+			&movie.Distributor, &movie.Budget, &movie.MPARating,
+			&revenueWorldwide, &revenueOpeningWeekendUSA, &currency, &source, &lastUpdated,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan movie: %w", err)
+		}
 
-	// Set box office data if available
-	if revenueWorldwide.Valid {
-		boxOffice.Revenue.Worldwide = revenueWorldwide.Int64
-		if revenueOpeningWeekendUSA.Valid {
-			boxOffice.Revenue.OpeningWeekendUSA = &revenueOpeningWeekendUSA.Int64
+		// Set box office data if available
+		if revenueWorldwide.Valid {
+			boxOffice.Revenue.Worldwide = revenueWorldwide.Int64
+			if revenueOpeningWeekendUSA.Valid {
+				boxOffice.Revenue.OpeningWeekendUSA = &revenueOpeningWeekendUSA.Int64
+			}
+			boxOffice.Currency = currency.String
+			boxOffice.Source = source.String
+			boxOffice.LastUpdated = lastUpdated.Time
+			movie.BoxOffice = &boxOffice
 		}
-		boxOffice.Currency = currency.String
-		boxOffice.Source = source.String
-		boxOffice.LastUpdated = lastUpdated.Time
-		movie.BoxOffice = &boxOffice
+
+		movies = append(movies, movie)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
 	}
 
-	return &movie, nil
+	return movies, nil
 }
 
+// averageRatingExpr is a movie's mean rating from movie_rating_stats, 0 when unrated.
+const averageRatingExpr = "COALESCE(s.rating_sum / NULLIF(s.rating_count, 0), 0)"
+
 func (r *MovieRepository) List(filters map[string]interface{}, limit int, cursor string) ([]models.Movie, *string, error) {
 	query := `
-		SELECT m.id, m.title, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
-		       b.revenue_worldwide, b.revenue_opening_weekend_usa, b.currency, b.source, b.last_updated
+		SELECT m.id, m.title, m.slug, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
+		       b.revenue_worldwide, b.revenue_opening_weekend_usa, b.currency, b.source, b.last_updated,
+		       ` + averageRatingExpr + `::text
 		FROM movies m
 		LEFT JOIN box_office b ON m.id = b.movie_id
+		LEFT JOIN movie_rating_stats s ON m.id = s.movie_id
 		WHERE 1=1
 	`
 	args := []interface{}{}
@@ -142,27 +230,51 @@ func (r *MovieRepository) List(filters map[string]interface{}, limit int, cursor
 		argCount++
 	}
 
+	if minRating, ok := filters["minRating"].(float64); ok {
+		query += fmt.Sprintf(" AND s.rating_count > 0 AND %s >= $%d", averageRatingExpr, argCount)
+		args = append(args, minRating)
+		argCount++
+	}
+
+	sortByRating := filters["sort"] == "rating"
+
 	// Apply cursor
-	if cursor != "" {
+	if cursor != "" && sortByRating {
+		value, id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !validCursorValue(value, cursorNumeric) {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (%s, m.id) < ($%d::numeric, $%d)", averageRatingExpr, argCount, argCount+1)
+		args = append(args, value, id)
+		argCount += 2
+	} else if cursor != "" {
 		query += fmt.Sprintf(" AND m.id > $%d", argCount)
 		args = append(args, cursor)
 		argCount++
 	}
 
 	// Order and limit
-	query += " ORDER BY m.id ASC"
+	if sortByRating {
+		query += " ORDER BY " + averageRatingExpr + " DESC, m.id DESC"
+	} else {
+		query += " ORDER BY m.id ASC"
+	}
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argCount)
 		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.replica.Query(query, args...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to list movies: %w", err)
 	}
 	defer rows.Close()
 
 	var movies []models.Movie
+	var averages []string
 	for rows.Next() {
 		var movie models.Movie
 		var boxOffice models.BoxOffice
@@ -171,11 +283,13 @@ func (r *MovieRepository) List(filters map[string]interface{}, limit int, cursor
 		var currency sql.NullString
 		var source sql.NullString
 		var lastUpdated sql.NullTime
+		var average string
 
 		err := rows.Scan(
-			&movie.ID, &movie.Title, &movie.Genre, &movie.ReleaseDate,
+			&movie.ID, &movie.Title, &movie.Slug, &movie.Genre, &movie.ReleaseDate,
 			&movie.Distributor, &movie.Budget, &movie.MPARating,
 			&revenueWorldwide, &revenueOpeningWeekendUSA, &currency, &source, &lastUpdated,
+			&average,
 		)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to scan movie: %w", err)
@@ -194,6 +308,7 @@ func (r *MovieRepository) List(filters map[string]interface{}, limit int, cursor
 		}
 
 		movies = append(movies, movie)
+		averages = append(averages, average)
 	}
 
 	// Determine next cursor
@@ -201,8 +316,105 @@ func (r *MovieRepository) List(filters map[string]interface{}, limit int, cursor
 	if limit > 0 && len(movies) > limit {
 		movies = movies[:limit]
 		lastID := movies[len(movies)-1].ID
+		if sortByRating {
+			lastID = encodeCursor(averages[limit-1], lastID)
+		}
 		nextCursor = &lastID
 	}
 
 	return movies, nextCursor, nil
 }
+
+// GetByIDs returns the movies with the given ids keyed by id, without box
+// office data. Unknown ids are skipped.
+func (r *MovieRepository) GetByIDs(ids []string) (map[string]models.Movie, error) {
+	query := `
+		SELECT id, title, slug, genre, release_date, distributor, budget, mpa_rating
+		FROM movies
+		WHERE id = ANY($1)
+	`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movies: %w", err)
+	}
+	defer rows.Close()
+
+	movies := make(map[string]models.Movie, len(ids))
+	for rows.Next() {
+		var movie models.Movie
+		err := rows.Scan(
+			&movie.ID, &movie.Title, &movie.Slug, &movie.Genre, &movie.ReleaseDate,
+			&movie.Distributor, &movie.Budget, &movie.MPARating,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan movie: %w", err)
+		}
+		movies[movie.ID] = movie
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get movies: %w", err)
+	}
+
+	return movies, nil
+}
+
+// FindSimilar scores every other movie against movieID by the weighted sum of
+// shared metadata (genre, distributor, decade, MPA rating, budget tier) and
+// the stored co-rating similarity, returning the best matches. It reads from
+// the replica.
+func (r *MovieRepository) FindSimilar(movieID string, weights models.SimilarityWeights, limit int) ([]models.SimilarityMatch, error) {
+	query := `
+		SELECT * FROM (
+			SELECT id, title, slug, genre, release_date, distributor, budget, mpa_rating,
+			       same_genre, same_distributor, same_era, same_mpa_rating, same_budget_tier, co_rating,
+			       $2::float8 * same_genre::int + $3::float8 * same_distributor::int + $4::float8 * same_era::int +
+			       $5::float8 * same_mpa_rating::int + $6::float8 * same_budget_tier::int + $7::float8 * co_rating AS score
+			FROM (
+				SELECT m.id, m.title, m.slug, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
+				       LOWER(m.genre) = LOWER(t.genre) AS same_genre,
+				       COALESCE(LOWER(m.distributor) = LOWER(t.distributor), false) AS same_distributor,
+				       EXTRACT(DECADE FROM m.release_date) = EXTRACT(DECADE FROM t.release_date) AS same_era,
+				       COALESCE(m.mpa_rating = t.mpa_rating, false) AS same_mpa_rating,
+				       COALESCE(width_bucket(m.budget, ARRAY[10000000, 50000000, 150000000]::bigint[]) =
+				                width_bucket(t.budget, ARRAY[10000000, 50000000, 150000000]::bigint[]), false) AS same_budget_tier,
+				       COALESCE(n.similarity, 0) AS co_rating
+				FROM movies m
+				JOIN movies t ON t.id = $1
+				LEFT JOIN movie_neighbors n ON n.movie_id = t.id AND n.neighbor_id = m.id
+				WHERE m.id <> t.id
+			) candidates
+		) scored
+		WHERE score > 0
+		ORDER BY score DESC, id ASC
+		LIMIT $8
+	`
+
+	rows, err := r.replica.Query(query, movieID,
+		weights.Genre, weights.Distributor, weights.Era, weights.MPARating, weights.BudgetTier, weights.CoRating,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar movies: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.SimilarityMatch
+	for rows.Next() {
+		var m models.SimilarityMatch
+		err := rows.Scan(
+			&m.Movie.ID, &m.Movie.Title, &m.Movie.Slug, &m.Movie.Genre, &m.Movie.ReleaseDate,
+			&m.Movie.Distributor, &m.Movie.Budget, &m.Movie.MPARating,
+			&m.SameGenre, &m.SameDistributor, &m.SameEra, &m.SameMPARating, &m.SameBudgetTier, &m.CoRating,
+			&m.Score,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar movie: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find similar movies: %w", err)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"robin-camp/internal/models"
+)
+
+type NeighborRepository struct {
+	db *sql.DB
+	// replica serves reads that can tolerate replication lag; it is db when
+	// no replica is configured
+	replica *sql.DB
+}
+
+func NewNeighborRepository(db, replica *sql.DB) *NeighborRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &NeighborRepository{db: db, replica: replica}
+}
+
+// ReplaceAll swaps the whole neighbour table for a freshly computed one in
+// a single transaction, so readers never see a partial rebuild.
+func (r *NeighborRepository) ReplaceAll(neighbors []models.MovieNeighbor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM movie_neighbors"); err != nil {
+		return fmt.Errorf("failed to clear movie neighbors: %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("movie_neighbors", "movie_id", "neighbor_id", "similarity", "co_raters"))
+	if err != nil {
+		return fmt.Errorf("failed to prepare movie neighbors copy: %w", err)
+	}
+
+	for _, n := range neighbors {
+		if _, err := stmt.Exec(n.MovieID, n.NeighborID, n.Similarity, n.CoRaters); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy movie neighbor: %w", err)
+		}
+	}
+
+	// Flush buffered rows
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to copy movie neighbors: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to copy movie neighbors: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListForMovies returns the stored neighbours of each given movie, read from
+// the replica; the table is only rebuilt periodically anyway.
+func (r *NeighborRepository) ListForMovies(movieIDs []string) ([]models.MovieNeighbor, error) {
+	query := `
+		SELECT movie_id, neighbor_id, similarity, co_raters
+		FROM movie_neighbors
+		WHERE movie_id = ANY($1)
+	`
+
+	rows, err := r.replica.Query(query, pq.Array(movieIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list movie neighbors: %w", err)
+	}
+	defer rows.Close()
+
+	var neighbors []models.MovieNeighbor
+	for rows.Next() {
+		var n models.MovieNeighbor
+		if err := rows.Scan(&n.MovieID, &n.NeighborID, &n.Similarity, &n.CoRaters); err != nil {
+			return nil, fmt.Errorf("failed to scan movie neighbor: %w", err)
+		}
+		neighbors = append(neighbors, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list movie neighbors: %w", err)
+	}
+
+	return neighbors, nil
+}
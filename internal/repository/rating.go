@@ -4,56 +4,645 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
+	"strconv"
 
 	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
 )
 
 type RatingRepository struct {
 	db *sql.DB
+	// replica serves reads that can tolerate replication lag; it is db when
+	// no replica is configured
+	replica *sql.DB
 }
 
-func NewRatingRepository(db *sql.DB) *RatingRepository {
-	return &RatingRepository{db: db}
+func NewRatingRepository(db, replica *sql.DB) *RatingRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &RatingRepository{db: db, replica: replica}
 }
 
-func (r *RatingRepository) Upsert(movieID, raterID string, rating float64) (bool, error) {
+// Upsert writes the rating and applies the change to movie_rating_stats in
+// the same transaction. A quarantined rating stays quarantined and out of
+// the stats when it is changed. A nil review leaves any stored review untouched;
+// otherwise it replaces it, keeping the original review creation time.
+// Resubmitting the stored text unchanged is a no-op for the review. A
+// rewritten review moves to moderation.EditStatus through the state machine,
+// with the change logged, and its votes and reports are discarded since they
+// were about the old text. The stored review, if any, is returned.
+func (r *RatingRepository) Upsert(movieID, raterID string, rating float64, review *models.ReviewInput) (bool, *models.Review, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := lockStats(tx, movieID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Read the previous value so it can be taken out of the stats
+	var previous float64
+	var quarantined bool
+	var previousStatus, previousHeadline, previousBody sql.NullString
+	err = tx.QueryRow(`
+		SELECT rating, quarantined, CASE WHEN review_body IS NOT NULL THEN review_status END,
+		       review_headline, review_body
+		FROM ratings WHERE movie_id = $1 AND rater_id = $2
+	`, movieID, raterID).Scan(&previous, &quarantined, &previousStatus, &previousHeadline, &previousBody)
+	if err != nil && err != sql.ErrNoRows {
+		return false, nil, fmt.Errorf("failed to get previous rating: %w", err)
+	}
+
+	// The same text again leaves the review, its status and its feedback alone
+	if review != nil && review.Body != nil && previousBody.Valid &&
+		*review.Body == previousBody.String && sameText(review.Headline, previousHeadline) {
+		review = nil
+	}
+
+	setReview := review != nil
+	edited := setReview && previousBody.Valid
+	var headline, body *string
+	status := moderation.StatusApproved
+	if setReview {
+		headline, body = review.Headline, review.Body
+		if review.Status != "" {
+			status = review.Status
+		}
+	}
+	if edited && body != nil {
+		status = moderation.EditStatus(previousStatus.String, status)
+		if status != previousStatus.String && !moderation.CanTransition(previousStatus.String, status) {
+			return false, nil, fmt.Errorf("invalid transition")
+		}
+	}
+
 	query := `
-		INSERT INTO ratings (movie_id, rater_id, rating)
-		VALUES ($1, $2, $3)
+		INSERT INTO ratings (movie_id, rater_id, rating, review_headline, review_body, review_status,
+		                     review_created_at, review_updated_at)
+		VALUES ($1, $2, $3, $5, $6, $7,
+		        CASE WHEN $6::text IS NOT NULL THEN CURRENT_TIMESTAMP END,
+		        CASE WHEN $6::text IS NOT NULL THEN CURRENT_TIMESTAMP END)
 		ON CONFLICT (movie_id, rater_id)
-		DO UPDATE SET rating = $3, created_at = CURRENT_TIMESTAMP
-		RETURNING (xmax = 0) AS inserted
+		DO UPDATE SET rating = $3, created_at = CURRENT_TIMESTAMP,
+			review_headline = CASE WHEN $4 THEN EXCLUDED.review_headline ELSE ratings.review_headline END,
+			review_body = CASE WHEN $4 THEN EXCLUDED.review_body ELSE ratings.review_body END,
+			review_status = CASE WHEN $4 THEN EXCLUDED.review_status ELSE ratings.review_status END,
+			review_created_at = CASE
+				WHEN NOT $4 THEN ratings.review_created_at
+				WHEN EXCLUDED.review_body IS NULL THEN NULL
+				ELSE COALESCE(ratings.review_created_at, CURRENT_TIMESTAMP) END,
+			review_updated_at = CASE
+				WHEN NOT $4 THEN ratings.review_updated_at
+				WHEN EXCLUDED.review_body IS NULL THEN NULL
+				ELSE CURRENT_TIMESTAMP END
+		RETURNING id, (xmax = 0) AS inserted, review_headline, review_body, review_helpful_count,
+		          review_unhelpful_count, review_status, review_created_at, review_updated_at
 	`
 
+	var ratingID int64
 	var inserted bool
-	err := r.db.QueryRow(query, movieID, raterID, rating).Scan(&inserted)
+	var storedHeadline, storedBody sql.NullString
+	var helpfulCount, unhelpfulCount int
+	var storedStatus string
+	var createdAt, updatedAt sql.NullTime
+	err = tx.QueryRow(query, movieID, raterID, rating, setReview, headline, body, status).Scan(
+		&ratingID, &inserted, &storedHeadline, &storedBody, &helpfulCount, &unhelpfulCount, &storedStatus,
+		&createdAt, &updatedAt,
+	)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to upsert rating: %w", err)
+	}
+
+	// Remember when the rater was first seen
+	_, err = tx.Exec(`
+		INSERT INTO raters (rater_id, first_rating_id)
+		VALUES ($1, $2)
+		ON CONFLICT (rater_id) DO NOTHING
+	`, raterID, ratingID)
 	if err != nil {
-		return false, fmt.Errorf("failed to upsert rating: %w", err)
+		return false, nil, fmt.Errorf("failed to record rater: %w", err)
+	}
+
+	// Every new review passes through the content filters; edits are logged
+	// when they change the status
+	if setReview && body != nil && (!edited || status != previousStatus.String) {
+		var fromStatus *string
+		reason := review.ModerationReason
+		if edited {
+			fromStatus = &previousStatus.String
+			if reason == "" {
+				reason = "review edited"
+			}
+		}
+		if _, err := logModeration(tx, ratingID, "system", fromStatus, status, reason); err != nil {
+			return false, nil, err
+		}
+	}
+
+	// Votes and reports were about the old text
+	if edited {
+		if err := resetFeedback(tx, ratingID); err != nil {
+			return false, nil, err
+		}
+		helpfulCount, unhelpfulCount = 0, 0
 	}
 
-	return inserted, nil
+	var stored *models.Review
+	if storedBody.Valid {
+		stored = &models.Review{
+			RaterID:        raterID,
+			Rating:         rating,
+			Body:           storedBody.String,
+			HelpfulCount:   helpfulCount,
+			UnhelpfulCount: unhelpfulCount,
+			Status:         storedStatus,
+			CreatedAt:      createdAt.Time,
+			UpdatedAt:      updatedAt.Time,
+			Edited:         updatedAt.Time.After(createdAt.Time),
+		}
+		if storedHeadline.Valid {
+			stored.Headline = &storedHeadline.String
+		}
+	}
+
+	if !quarantined {
+		if !inserted {
+			applyRating(stats, previous, -1)
+		}
+		applyRating(stats, rating, 1)
+		if err := saveStats(tx, stats); err != nil {
+			return false, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, nil, fmt.Errorf("failed to commit rating: %w", err)
+	}
+
+	return inserted, stored, nil
 }
 
-func (r *RatingRepository) GetAggregate(movieID string) (*models.RatingAggregate, error) {
-	query := `
-		SELECT COALESCE(AVG(rating), 0) as average, COUNT(*) as count
-		FROM ratings
-		WHERE movie_id = $1
-	`
+// Delete removes a rater's rating and takes it out of movie_rating_stats in
+// the same transaction. It reports whether a rating existed.
+func (r *RatingRepository) Delete(movieID, raterID string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	var avg float64
-	var count int
+	stats, err := lockStats(tx, movieID)
+	if err != nil {
+		return false, err
+	}
 
-	err := r.db.QueryRow(query, movieID).Scan(&avg, &count)
+	var previous float64
+	var quarantined bool
+	err = tx.QueryRow(`
+		DELETE FROM ratings
+		WHERE movie_id = $1 AND rater_id = $2
+		RETURNING rating, quarantined
+	`, movieID, raterID).Scan(&previous, &quarantined)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete rating: %w", err)
+	}
+
+	if !quarantined {
+		applyRating(stats, previous, -1)
+		if err := saveStats(tx, stats); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit rating deletion: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetAggregate reads the materialized stats rather than scanning ratings,
+// so quarantined ratings are not counted.
+func (r *RatingRepository) GetAggregate(movieID string, prior models.RatingPrior) (*models.RatingAggregate, error) {
+	stats, err := r.getStats(movieID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rating aggregate: %w", err)
 	}
 
+	var avg float64
+	if stats.Count > 0 {
+		avg = stats.Sum / float64(stats.Count)
+	}
+
+	weighted := weightedAverage(avg, stats.Count, prior)
+
 	// Round to 1 decimal place
 	avg = math.Round(avg*10) / 10
 
 	return &models.RatingAggregate{
-		Average: avg,
-		Count:   count,
+		Average:         avg,
+		WeightedAverage: weighted,
+		Count:           stats.Count,
 	}, nil
 }
+
+// weightedAverage blends mean with the prior mean in proportion to the
+// number of real ratings versus the prior's virtual votes, rounded to
+// 2 decimal places.
+func weightedAverage(mean float64, count int, prior models.RatingPrior) float64 {
+	votes := float64(count + prior.MinVotes)
+	if votes == 0 {
+		return 0
+	}
+	weighted := (mean*float64(count) + prior.Mean*float64(prior.MinVotes)) / votes
+	return math.Round(weighted*100) / 100
+}
+
+// GetAggregateDetail returns the aggregate together with the per-value
+// distribution, median and population standard deviation, all derived from
+// the materialized bucket counts.
+func (r *RatingRepository) GetAggregateDetail(movieID string, prior models.RatingPrior) (*models.RatingAggregate, error) {
+	stats, err := r.getStats(movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating distribution: %w", err)
+	}
+
+	buckets := make([]models.RatingBucket, len(models.RatingScale))
+	for i, v := range models.RatingScale {
+		buckets[i] = models.RatingBucket{Rating: v, Count: int(stats.Buckets[i])}
+	}
+	aggregate := &models.RatingAggregate{Distribution: buckets}
+
+	var sum float64
+	for _, b := range buckets {
+		aggregate.Count += b.Count
+		sum += b.Rating * float64(b.Count)
+	}
+
+	var mean, median, stdDev float64
+	if aggregate.Count > 0 {
+		mean = sum / float64(aggregate.Count)
+
+		var variance float64
+		for _, b := range buckets {
+			variance += float64(b.Count) * (b.Rating - mean) * (b.Rating - mean)
+		}
+		variance /= float64(aggregate.Count)
+
+		median = bucketMedian(buckets, aggregate.Count)
+		stdDev = math.Round(math.Sqrt(variance)*100) / 100
+		aggregate.Average = math.Round(mean*10) / 10
+	}
+	aggregate.WeightedAverage = weightedAverage(mean, aggregate.Count, prior)
+	aggregate.Median = &median
+	aggregate.StdDev = &stdDev
+
+	return aggregate, nil
+}
+
+// bucketMedian finds the median of total ratings laid out in ascending
+// buckets, averaging the two middle values when total is even.
+func bucketMedian(buckets []models.RatingBucket, total int) float64 {
+	valueAt := func(pos int) float64 {
+		seen := 0
+		for _, b := range buckets {
+			seen += b.Count
+			if pos < seen {
+				return b.Rating
+			}
+		}
+		return 0
+	}
+
+	if total%2 == 1 {
+		return valueAt(total / 2)
+	}
+	return (valueAt(total/2-1) + valueAt(total/2)) / 2
+}
+
+// TopRated ranks movies by their Bayesian weighted rating, skipping
+// movies with fewer than minCount ratings. It reads from the replica.
+func (r *RatingRepository) TopRated(filters map[string]interface{}, prior models.RatingPrior, minCount, limit int) ([]models.TopMovie, error) {
+	query := `
+		SELECT m.id, m.title, m.slug, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
+		       s.rating_sum / s.rating_count, s.rating_count
+		FROM movies m
+		JOIN movie_rating_stats s ON s.movie_id = m.id
+		WHERE s.rating_count > 0
+	`
+	args := []interface{}{prior.Mean, prior.MinVotes}
+	argCount := 3
+
+	// Apply filters
+	if genre, ok := filters["genre"].(string); ok && genre != "" {
+		query += fmt.Sprintf(" AND LOWER(m.genre) = LOWER($%d)", argCount)
+		args = append(args, genre)
+		argCount++
+	}
+
+	if year, ok := filters["year"].(int); ok {
+		query += fmt.Sprintf(" AND EXTRACT(YEAR FROM m.release_date) = $%d", argCount)
+		args = append(args, year)
+		argCount++
+	}
+
+	query += fmt.Sprintf(" AND s.rating_count >= $%d", argCount)
+	args = append(args, minCount)
+	argCount++
+
+	query += ` ORDER BY (s.rating_sum + $1::numeric * $2::integer) / (s.rating_count + $2::integer) DESC,
+		s.rating_count DESC, m.id ASC`
+	query += fmt.Sprintf(" LIMIT $%d", argCount)
+	args = append(args, limit)
+
+	rows, err := r.replica.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list top rated movies: %w", err)
+	}
+	defer rows.Close()
+
+	var top []models.TopMovie
+	for rows.Next() {
+		var item models.TopMovie
+		var avg float64
+		err := rows.Scan(
+			&item.Movie.ID, &item.Movie.Title, &item.Movie.Slug, &item.Movie.Genre, &item.Movie.ReleaseDate,
+			&item.Movie.Distributor, &item.Movie.Budget, &item.Movie.MPARating,
+			&avg, &item.Count,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan top rated movie: %w", err)
+		}
+
+		item.Rank = len(top) + 1
+		item.WeightedAverage = weightedAverage(avg, item.Count, prior)
+		item.Average = math.Round(avg*10) / 10
+		top = append(top, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list top rated movies: %w", err)
+	}
+
+	return top, nil
+}
+
+// Trending ranks movies by ratings created or updated within the given
+// Postgres interval, ignoring quarantined ratings. With weighted set, each rating counts by its value
+// instead of as one. It reads from the replica.
+func (r *RatingRepository) Trending(interval string, weighted bool, limit int) ([]models.TrendingMovie, error) {
+	score := "COUNT(r.id)"
+	if weighted {
+		score = "SUM(r.rating)"
+	}
+
+	query := `
+		SELECT m.id, m.title, m.slug, m.genre, m.release_date, m.distributor, m.budget, m.mpa_rating,
+		       ` + score + ` AS score, COUNT(r.id), AVG(r.rating)
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.created_at >= CURRENT_TIMESTAMP - $1::interval AND NOT r.quarantined
+		GROUP BY m.id
+		ORDER BY score DESC, COUNT(r.id) DESC, m.id ASC
+		LIMIT $2
+	`
+
+	rows, err := r.replica.Query(query, interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trending movies: %w", err)
+	}
+	defer rows.Close()
+
+	var trending []models.TrendingMovie
+	for rows.Next() {
+		var item models.TrendingMovie
+		var avg float64
+		err := rows.Scan(
+			&item.Movie.ID, &item.Movie.Title, &item.Movie.Slug, &item.Movie.Genre, &item.Movie.ReleaseDate,
+			&item.Movie.Distributor, &item.Movie.Budget, &item.Movie.MPARating,
+			&item.Score, &item.RatingCount, &avg,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trending movie: %w", err)
+		}
+
+		item.Rank = len(trending) + 1
+		item.Average = math.Round(avg*10) / 10
+		trending = append(trending, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list trending movies: %w", err)
+	}
+
+	return trending, nil
+}
+
+// ListMatrix returns every rating outside quarantine as a (movie, rater,
+// rating) triple. It stays on the primary: the neighbour rebuild runs in
+// the background, where lag buys nothing, and should see every rating.
+func (r *RatingRepository) ListMatrix() ([]models.RatingEntry, error) {
+	rows, err := r.db.Query(`SELECT movie_id, rater_id, rating FROM ratings WHERE NOT quarantined`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.RatingEntry
+	for rows.Next() {
+		var entry models.RatingEntry
+		if err := rows.Scan(&entry.MovieID, &entry.RaterID, &entry.Rating); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list ratings: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetRaterVector returns a rater's ratings keyed by movie id. Like the other
+// per-rater reads below it stays on the primary, so a rater's own ratings
+// show up (and are excluded from recommendations) right after they are made.
+func (r *RatingRepository) GetRaterVector(raterID string) (map[string]float64, error) {
+	rows, err := r.db.Query(`SELECT movie_id, rating FROM ratings WHERE rater_id = $1`, raterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rater ratings: %w", err)
+	}
+	defer rows.Close()
+
+	vector := make(map[string]float64)
+	for rows.Next() {
+		var movieID string
+		var rating float64
+		if err := rows.Scan(&movieID, &rating); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		vector[movieID] = rating
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get rater ratings: %w", err)
+	}
+
+	return vector, nil
+}
+
+type ratingSort struct {
+	column string
+	desc   bool
+}
+
+var raterRatingSorts = map[string]ratingSort{
+	"newest":  {column: "r.created_at", desc: true},
+	"oldest":  {column: "r.created_at", desc: false},
+	"highest": {column: "r.rating", desc: true},
+	"lowest":  {column: "r.rating", desc: false},
+}
+
+func (r *RatingRepository) ListByRater(raterID, sort string, limit int, cursor string) ([]models.RaterRating, *string, error) {
+	order, ok := raterRatingSorts[sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid sort")
+	}
+
+	cast := cursorTimestamp
+	if order.column == "r.rating" {
+		cast = cursorNumeric
+	}
+	cmp, dir := ">", "ASC"
+	if order.desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	query := `
+		SELECT r.id, r.movie_id, m.title, r.rating, r.created_at
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.rater_id = $1
+	`
+	args := []interface{}{raterID}
+	argCount := 2
+
+	// Apply cursor
+	if cursor != "" {
+		value, idStr, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || !validCursorValue(value, cast) {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (%s, r.id) %s ($%d::%s, $%d)", order.column, cmp, argCount, cast, argCount+1)
+		args = append(args, value, id)
+		argCount += 2
+	}
+
+	// Order and limit
+	query += fmt.Sprintf(" ORDER BY %s %s, r.id %s", order.column, dir, dir)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list rater ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []models.RaterRating
+	var ids []int64
+	for rows.Next() {
+		var rating models.RaterRating
+		var id int64
+		if err := rows.Scan(&id, &rating.MovieID, &rating.MovieTitle, &rating.Rating, &rating.RatedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		ratings = append(ratings, rating)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list rater ratings: %w", err)
+	}
+
+	// Determine next cursor
+	var nextCursor *string
+	if limit > 0 && len(ratings) > limit {
+		ratings = ratings[:limit]
+		last := ratings[limit-1]
+		value := last.RatedAt.Format("2006-01-02T15:04:05.999999")
+		if order.column == "r.rating" {
+			value = strconv.FormatFloat(last.Rating, 'f', 1, 64)
+		}
+		next := encodeCursor(value, strconv.FormatInt(ids[limit-1], 10))
+		nextCursor = &next
+	}
+
+	return ratings, nextCursor, nil
+}
+
+func (r *RatingRepository) GetRaterStats(raterID string) (*models.RaterStats, error) {
+	query := `
+		SELECT rating, COUNT(*)
+		FROM ratings
+		WHERE rater_id = $1
+		GROUP BY rating
+	`
+
+	rows, err := r.db.Query(query, raterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rater stats: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[float64]int)
+	for rows.Next() {
+		var rating float64
+		var count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan rater stats: %w", err)
+		}
+		counts[rating] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get rater stats: %w", err)
+	}
+
+	stats := &models.RaterStats{Distribution: buildDistribution(counts)}
+	var sum float64
+	for rating, count := range counts {
+		stats.Count += count
+		sum += rating * float64(count)
+	}
+	if stats.Count > 0 {
+		stats.Mean = math.Round(sum/float64(stats.Count)*10) / 10
+	}
+
+	return stats, nil
+}
+
+// buildDistribution expands per-value counts into one bucket per rating
+// on the scale, so empty buckets are reported as zero.
+func buildDistribution(counts map[float64]int) []models.RatingBucket {
+	buckets := make([]models.RatingBucket, len(models.RatingScale))
+	for i, v := range models.RatingScale {
+		buckets[i] = models.RatingBucket{Rating: v, Count: counts[v]}
+	}
+	return buckets
+}
+
+// sameText reports whether an optional submitted headline equals a stored one.
+func sameText(submitted *string, stored sql.NullString) bool {
+	if submitted == nil {
+		return !stored.Valid
+	}
+	return stored.Valid && *submitted == stored.String
+}
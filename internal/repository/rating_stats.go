@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lib/pq"
+
+	"robin-camp/internal/models"
+)
+
+// bucketIndex maps a rating value to its position in models.RatingScale.
+func bucketIndex(rating float64) int {
+	return int(math.Round(rating*2)) - 1
+}
+
+func emptyBuckets() []int64 {
+	return make([]int64, len(models.RatingScale))
+}
+
+// lockStats loads the stats row of a movie with a row lock, creating it
+// first if needed. Holding the lock serializes rating writes per movie so
+// the deltas applied by the caller cannot interleave.
+func lockStats(tx *sql.Tx, movieID string) (*models.RatingStats, error) {
+	_, err := tx.Exec(`
+		INSERT INTO movie_rating_stats (movie_id)
+		VALUES ($1)
+		ON CONFLICT (movie_id) DO NOTHING
+	`, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rating stats: %w", err)
+	}
+
+	stats := &models.RatingStats{MovieID: movieID}
+	var buckets pq.Int64Array
+	err = tx.QueryRow(`
+		SELECT rating_sum, rating_count, bucket_counts
+		FROM movie_rating_stats
+		WHERE movie_id = $1
+		FOR UPDATE
+	`, movieID).Scan(&stats.Sum, &stats.Count, &buckets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock rating stats: %w", err)
+	}
+
+	stats.Buckets = emptyBuckets()
+	copy(stats.Buckets, buckets)
+
+	return stats, nil
+}
+
+// applyRating adds (delta = 1) or removes (delta = -1) one rating.
+func applyRating(stats *models.RatingStats, rating float64, delta int) {
+	stats.Sum += rating * float64(delta)
+	stats.Count += delta
+	stats.Buckets[bucketIndex(rating)] += int64(delta)
+}
+
+func saveStats(tx *sql.Tx, stats *models.RatingStats) error {
+	_, err := tx.Exec(`
+		UPDATE movie_rating_stats
+		SET rating_sum = $2, rating_count = $3, bucket_counts = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE movie_id = $1
+	`, stats.MovieID, stats.Sum, stats.Count, pq.Array(stats.Buckets))
+	if err != nil {
+		return fmt.Errorf("failed to update rating stats: %w", err)
+	}
+	return nil
+}
+
+// getStats reads from the replica; it backs the aggregate endpoints only.
+func (r *RatingRepository) getStats(movieID string) (*models.RatingStats, error) {
+	stats := &models.RatingStats{MovieID: movieID, Buckets: emptyBuckets()}
+	var buckets pq.Int64Array
+	err := r.replica.QueryRow(`
+		SELECT rating_sum, rating_count, bucket_counts
+		FROM movie_rating_stats
+		WHERE movie_id = $1
+	`, movieID).Scan(&stats.Sum, &stats.Count, &buckets)
+
+	if err == sql.ErrNoRows {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating stats: %w", err)
+	}
+
+	copy(stats.Buckets, buckets)
+	return stats, nil
+}
+
+// ReconcileStats recomputes every movie's stats from the non-quarantined
+// ratings and returns the rows whose materialized values have drifted.
+// Both sides are read from one REPEATABLE READ snapshot so that a report
+// compares consistent data. With apply set, drifted rows are rewritten while
+// rating writes are blocked.
+func (r *RatingRepository) ReconcileStats(apply bool) ([]models.RatingStatsDrift, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  !apply,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rating writes lock movie_rating_stats (lockStats) before touching
+	// ratings, so take the table locks in that same order to avoid
+	// deadlocking with them. Locks must precede the first query, which fixes
+	// the snapshot.
+	if apply {
+		if _, err := tx.Exec("LOCK TABLE movie_rating_stats IN EXCLUSIVE MODE"); err != nil {
+			return nil, fmt.Errorf("failed to lock rating stats: %w", err)
+		}
+		if _, err := tx.Exec("LOCK TABLE ratings IN SHARE MODE"); err != nil {
+			return nil, fmt.Errorf("failed to lock ratings: %w", err)
+		}
+	}
+
+	// Recompute from scratch
+	expected := make(map[string]*models.RatingStats)
+	rows, err := tx.Query(`
+		SELECT movie_id, rating, COUNT(*)
+		FROM ratings
+		WHERE NOT quarantined
+		GROUP BY movie_id, rating
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recompute rating stats: %w", err)
+	}
+	for rows.Next() {
+		var movieID string
+		var rating float64
+		var count int
+		if err := rows.Scan(&movieID, &rating, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rating stats: %w", err)
+		}
+		stats, ok := expected[movieID]
+		if !ok {
+			stats = &models.RatingStats{MovieID: movieID, Buckets: emptyBuckets()}
+			expected[movieID] = stats
+		}
+		stats.Sum += rating * float64(count)
+		stats.Count += count
+		stats.Buckets[bucketIndex(rating)] += int64(count)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to recompute rating stats: %w", err)
+	}
+
+	// Load materialized values
+	actual := make(map[string]*models.RatingStats)
+	rows, err = tx.Query(`
+		SELECT movie_id, rating_sum, rating_count, bucket_counts
+		FROM movie_rating_stats
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rating stats: %w", err)
+	}
+	for rows.Next() {
+		stats := &models.RatingStats{Buckets: emptyBuckets()}
+		var buckets pq.Int64Array
+		if err := rows.Scan(&stats.MovieID, &stats.Sum, &stats.Count, &buckets); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rating stats: %w", err)
+		}
+		copy(stats.Buckets, buckets)
+		actual[stats.MovieID] = stats
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load rating stats: %w", err)
+	}
+
+	// Compare; a missing row is equivalent to all zeros
+	for movieID := range actual {
+		if _, ok := expected[movieID]; !ok {
+			expected[movieID] = &models.RatingStats{MovieID: movieID, Buckets: emptyBuckets()}
+		}
+	}
+
+	var drifts []models.RatingStatsDrift
+	for movieID, want := range expected {
+		got, ok := actual[movieID]
+		if !ok {
+			got = &models.RatingStats{MovieID: movieID, Buckets: emptyBuckets()}
+		}
+		if statsEqual(want, got) {
+			continue
+		}
+		drifts = append(drifts, models.RatingStatsDrift{
+			MovieID:  movieID,
+			Expected: *want,
+			Actual:   *got,
+		})
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].MovieID < drifts[j].MovieID
+	})
+
+	if !apply || len(drifts) == 0 {
+		return drifts, nil
+	}
+
+	for _, drift := range drifts {
+		want := drift.Expected
+		_, err := tx.Exec(`
+			INSERT INTO movie_rating_stats (movie_id, rating_sum, rating_count, bucket_counts)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (movie_id)
+			DO UPDATE SET rating_sum = $2, rating_count = $3, bucket_counts = $4, updated_at = CURRENT_TIMESTAMP
+		`, want.MovieID, want.Sum, want.Count, pq.Array(want.Buckets))
+		if err != nil {
+			return nil, fmt.Errorf("failed to repair rating stats for %s: %w", want.MovieID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit rating stats repair: %w", err)
+	}
+
+	return drifts, nil
+}
+
+func statsEqual(a, b *models.RatingStats) bool {
+	if a.Sum != b.Sum || a.Count != b.Count || len(a.Buckets) != len(b.Buckets) {
+		return false
+	}
+	for i := range a.Buckets {
+		if a.Buckets[i] != b.Buckets[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
+)
+
+var reviewSorts = map[string]string{
+	"newest":  "review_updated_at",
+	"highest": "rating",
+	"helpful": "review_helpful_count",
+}
+
+// ListReviews pages through the approved reviews of a movie, most recent,
+// highest rated or most helpful first.
+func (r *RatingRepository) ListReviews(movieID, sort string, limit int, cursor string) ([]models.Review, *string, error) {
+	column, ok := reviewSorts[sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid sort")
+	}
+
+	cast := cursorNumeric
+	if column == "review_updated_at" {
+		cast = cursorTimestamp
+	}
+
+	query := `
+		SELECT id, rater_id, rating, review_headline, review_body, review_helpful_count, review_unhelpful_count,
+		       review_status, review_created_at, review_updated_at, ` + column + `::text
+		FROM ratings
+		WHERE movie_id = $1 AND review_body IS NOT NULL AND review_status = $2
+	`
+	args := []interface{}{movieID, moderation.StatusApproved}
+	argCount := 3
+
+	// Apply cursor
+	if cursor != "" {
+		value, idStr, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || !validCursorValue(value, cast) {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (%s, id) < ($%d::%s, $%d)", column, argCount, cast, argCount+1)
+		args = append(args, value, id)
+		argCount += 2
+	}
+
+	// Order and limit
+	query += fmt.Sprintf(" ORDER BY %s DESC, id DESC", column)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []models.Review
+	var ids []int64
+	var sortValues []string
+	for rows.Next() {
+		var review models.Review
+		var id int64
+		var headline sql.NullString
+		var sortValue string
+		err := rows.Scan(
+			&id, &review.RaterID, &review.Rating, &headline, &review.Body, &review.HelpfulCount, &review.UnhelpfulCount,
+			&review.Status, &review.CreatedAt, &review.UpdatedAt, &sortValue,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		if headline.Valid {
+			review.Headline = &headline.String
+		}
+		review.Edited = review.UpdatedAt.After(review.CreatedAt)
+
+		reviews = append(reviews, review)
+		ids = append(ids, id)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	// Determine next cursor
+	var nextCursor *string
+	if limit > 0 && len(reviews) > limit {
+		reviews = reviews[:limit]
+		next := encodeCursor(sortValues[limit-1], strconv.FormatInt(ids[limit-1], 10))
+		nextCursor = &next
+	}
+
+	return reviews, nextCursor, nil
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
+)
+
+// lockReview returns the rating id of a rater's review on a movie with a
+// row lock held, so its status and counters can be changed without races.
+func lockReview(tx *sql.Tx, movieID, reviewerID string) (int64, error) {
+	var ratingID int64
+	err := tx.QueryRow(`
+		SELECT id FROM ratings
+		WHERE movie_id = $1 AND rater_id = $2 AND review_body IS NOT NULL
+		FOR UPDATE
+	`, movieID, reviewerID).Scan(&ratingID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock review: %w", err)
+	}
+	return ratingID, nil
+}
+
+// lockApprovedReview is lockReview for readers' feedback: only approved
+// reviews are listed publicly, so any other review is "review not found".
+func lockApprovedReview(tx *sql.Tx, movieID, reviewerID string) (int64, error) {
+	var ratingID int64
+	err := tx.QueryRow(`
+		SELECT id FROM ratings
+		WHERE movie_id = $1 AND rater_id = $2 AND review_body IS NOT NULL AND review_status = $3
+		FOR UPDATE
+	`, movieID, reviewerID, moderation.StatusApproved).Scan(&ratingID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock review: %w", err)
+	}
+	return ratingID, nil
+}
+
+// resetFeedback discards the votes and reports on a review, along with its
+// counters. It is called when the review is rewritten or removed, since the
+// feedback was about the old text.
+func resetFeedback(tx *sql.Tx, ratingID int64) error {
+	if _, err := tx.Exec(`DELETE FROM review_votes WHERE rating_id = $1`, ratingID); err != nil {
+		return fmt.Errorf("failed to clear votes: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM review_reports WHERE rating_id = $1`, ratingID); err != nil {
+		return fmt.Errorf("failed to clear reports: %w", err)
+	}
+	_, err := tx.Exec(`
+		UPDATE ratings
+		SET review_helpful_count = 0, review_unhelpful_count = 0, review_report_count = 0
+		WHERE id = $1
+	`, ratingID)
+	if err != nil {
+		return fmt.Errorf("failed to reset feedback counts: %w", err)
+	}
+	return nil
+}
+
+// VoteReview records a voter's helpful/unhelpful vote with upsert semantics
+// and keeps the review's vote counters in step. It reports whether the vote
+// is new and returns the updated counters.
+func (r *RatingRepository) VoteReview(movieID, reviewerID, voterID string, helpful bool) (bool, int, int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ratingID, err := lockApprovedReview(tx, movieID, reviewerID)
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	var previous sql.NullBool
+	err = tx.QueryRow(`
+		SELECT helpful FROM review_votes WHERE rating_id = $1 AND voter_id = $2
+	`, ratingID, voterID).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return false, 0, 0, fmt.Errorf("failed to get previous vote: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO review_votes (rating_id, voter_id, helpful)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (rating_id, voter_id)
+		DO UPDATE SET helpful = $3, updated_at = CURRENT_TIMESTAMP
+	`, ratingID, voterID, helpful)
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to upsert vote: %w", err)
+	}
+
+	var helpfulDelta, unhelpfulDelta int
+	if previous.Valid {
+		if previous.Bool {
+			helpfulDelta--
+		} else {
+			unhelpfulDelta--
+		}
+	}
+	if helpful {
+		helpfulDelta++
+	} else {
+		unhelpfulDelta++
+	}
+
+	var helpfulCount, unhelpfulCount int
+	err = tx.QueryRow(`
+		UPDATE ratings
+		SET review_helpful_count = review_helpful_count + $2,
+		    review_unhelpful_count = review_unhelpful_count + $3
+		WHERE id = $1
+		RETURNING review_helpful_count, review_unhelpful_count
+	`, ratingID, helpfulDelta, unhelpfulDelta).Scan(&helpfulCount, &unhelpfulCount)
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to update vote counts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, 0, fmt.Errorf("failed to commit vote: %w", err)
+	}
+
+	return !previous.Valid, helpfulCount, unhelpfulCount, nil
+}
+
+// ReportReview records a report against a review; reporting the same review
+// again replaces the reporter's earlier reason. An approved review is hidden
+// whenever a report finds its report count at or above hideThreshold, so a
+// review approved again after being hidden goes back into hiding on the next
+// report. It reports whether the report is new.
+func (r *RatingRepository) ReportReview(movieID, reviewerID, reporterID, reason string, details *string, hideThreshold int) (*models.ReviewReport, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ratingID, err := lockApprovedReview(tx, movieID, reviewerID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	report := &models.ReviewReport{
+		ReviewerID: reviewerID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+	}
+
+	var inserted bool
+	err = tx.QueryRow(`
+		INSERT INTO review_reports (rating_id, reporter_id, reason, details)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (rating_id, reporter_id)
+		DO UPDATE SET reason = $3, details = $4, created_at = CURRENT_TIMESTAMP
+		RETURNING (xmax = 0) AS inserted, created_at
+	`, ratingID, reporterID, reason, details).Scan(&inserted, &report.CreatedAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to upsert report: %w", err)
+	}
+
+	if inserted {
+		_, err = tx.Exec(`
+			UPDATE ratings SET review_report_count = review_report_count + 1 WHERE id = $1
+		`, ratingID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update report count: %w", err)
+		}
+	}
+
+	if err := hideIfReported(tx, ratingID, hideThreshold); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit report: %w", err)
+	}
+
+	return report, inserted, nil
+}
+
+// ListReportedReviews pages through reviews with at least one report, most
+// reported first, for the moderation queue.
+func (r *RatingRepository) ListReportedReviews(limit int, cursor string) ([]models.ReportedReview, *string, error) {
+	query := `
+		SELECT r.id, r.movie_id, m.title, r.rater_id, r.rating, r.review_headline, r.review_body,
+		       r.review_helpful_count, r.review_unhelpful_count, r.review_status,
+		       r.review_created_at, r.review_updated_at, r.review_report_count,
+		       (SELECT MAX(created_at) FROM review_reports WHERE rating_id = r.id)
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.review_report_count > 0 AND r.review_body IS NOT NULL
+	`
+	args := []interface{}{}
+	argCount := 1
+
+	// Apply cursor
+	if cursor != "" {
+		value, idStr, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid cursor")
+		}
+		query += fmt.Sprintf(" AND (r.review_report_count, r.id) < ($%d, $%d)", argCount, argCount+1)
+		args = append(args, count, id)
+		argCount += 2
+	}
+
+	// Order and limit
+	query += " ORDER BY r.review_report_count DESC, r.id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list reported reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reported []models.ReportedReview
+	var ids []int64
+	for rows.Next() {
+		var item models.ReportedReview
+		var id int64
+		var headline sql.NullString
+		err := rows.Scan(
+			&id, &item.MovieID, &item.MovieTitle, &item.Review.RaterID, &item.Review.Rating,
+			&headline, &item.Review.Body, &item.Review.HelpfulCount, &item.Review.UnhelpfulCount,
+			&item.Review.Status, &item.Review.CreatedAt, &item.Review.UpdatedAt, &item.ReportCount,
+			&item.LastReportedAt,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan reported review: %w", err)
+		}
+		if headline.Valid {
+			item.Review.Headline = &headline.String
+		}
+		item.Review.Edited = item.Review.UpdatedAt.After(item.Review.CreatedAt)
+
+		reported = append(reported, item)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list reported reviews: %w", err)
+	}
+
+	// Determine next cursor
+	var nextCursor *string
+	if limit > 0 && len(reported) > limit {
+		reported = reported[:limit]
+		last := reported[limit-1]
+		next := encodeCursor(strconv.Itoa(last.ReportCount), strconv.FormatInt(ids[limit-1], 10))
+		nextCursor = &next
+	}
+	ids = ids[:len(reported)]
+
+	// Break down report reasons for the page
+	if len(ids) > 0 {
+		byID := make(map[int64]*models.ReportedReview, len(ids))
+		for i := range reported {
+			reported[i].Reasons = make(map[string]int)
+			byID[ids[i]] = &reported[i]
+		}
+
+		reasonRows, err := r.db.Query(`
+			SELECT rating_id, reason, COUNT(*)
+			FROM review_reports
+			WHERE rating_id = ANY($1)
+			GROUP BY rating_id, reason
+		`, pq.Array(ids))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list report reasons: %w", err)
+		}
+		defer reasonRows.Close()
+
+		for reasonRows.Next() {
+			var id int64
+			var reason string
+			var count int
+			if err := reasonRows.Scan(&id, &reason, &count); err != nil {
+				return nil, nil, fmt.Errorf("failed to scan report reason: %w", err)
+			}
+			byID[id].Reasons[reason] = count
+		}
+		if err := reasonRows.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to list report reasons: %w", err)
+		}
+	}
+
+	return reported, nextCursor, nil
+}
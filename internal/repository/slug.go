@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// maxSlugLength caps the title part of a slug, before any "-<n>" suffix.
+const maxSlugLength = 80
+
+// reservedSlugs collide with fixed routes under /movies and are never
+// handed out as is.
+var reservedSlugs = map[string]bool{"top": true, "trending": true, "id": true}
+
+// slugBase turns a title into lowercase ASCII letters and digits separated
+// by single dashes, e.g. "Alien³: Director's Cut" becomes
+// "alien-director-s-cut". Titles with no usable characters become "movie".
+// Migration 011 backfills existing rows with the same rules.
+func slugBase(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(title) {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(c)
+			continue
+		}
+		dash = true
+	}
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		return "movie"
+	}
+	return slug
+}
+
+// nextSlug returns base, or base with the lowest "-<n>" suffix (n >= 2)
+// that no movie uses yet. Concurrent inserts can still race for the same
+// slug; the unique index rejects the loser, which retries.
+func nextSlug(ctx context.Context, tx *sql.Tx, base string) (string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT slug FROM movies WHERE slug = $1 OR slug LIKE $2`, base, base+"-%")
+	if err != nil {
+		return "", fmt.Errorf("failed to check slug: %w", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", fmt.Errorf("failed to check slug: %w", err)
+		}
+		taken[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to check slug: %w", err)
+	}
+
+	if !taken[base] && !reservedSlugs[base] {
+		return base, nil
+	}
+	for n := 2; ; n++ {
+		if slug := fmt.Sprintf("%s-%d", base, n); !taken[slug] {
+			return slug, nil
+		}
+	}
+}
+
+// isUniqueViolation reports whether err violates the named unique index or
+// constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
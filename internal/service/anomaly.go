@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/repository"
+)
+
+// burstTolerance is how far apart two scores may be and still count as
+// near-identical within a burst.
+const burstTolerance = 0.5
+
+// AnomalyService looks for rating brigading on a fixed interval and
+// quarantines the ratings it flags, keeping them out of aggregates until an
+// admin releases them.
+type AnomalyService struct {
+	ratingRepo *repository.RatingRepository
+	interval   time.Duration
+	thresholds models.AnomalyThresholds
+	audit      *AuditService
+}
+
+func NewAnomalyService(ratingRepo *repository.RatingRepository, interval time.Duration, thresholds models.AnomalyThresholds, audit *AuditService) *AnomalyService {
+	return &AnomalyService{
+		ratingRepo: ratingRepo,
+		interval:   interval,
+		thresholds: thresholds,
+		audit:      audit,
+	}
+}
+
+// Start scans immediately and then on each interval until ctx is cancelled.
+func (s *AnomalyService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.Scan(); err != nil {
+				slog.Error("failed to scan for rating anomalies", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *AnomalyService) Scan() error {
+	ratings, err := s.ratingRepo.ListRecentRatings(s.thresholds.Lookback)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range detectAnomalies(ratings, s.thresholds) {
+		anomaly, err := s.ratingRepo.QuarantineRatings(candidate)
+		if err != nil {
+			return err
+		}
+		if anomaly != nil {
+			slog.Warn("quarantined ratings",
+				"movie_id", anomaly.MovieID, "ratings", anomaly.RatingCount, "detail", anomaly.Detail)
+		}
+	}
+
+	return nil
+}
+
+// detectAnomalies groups ratings, which must be ordered by movie and time,
+// per movie and flags at most one burst and one group of extreme first
+// actions per movie. Ratings in a burst are not also counted as extreme.
+func detectAnomalies(ratings []models.RecentRating, thresholds models.AnomalyThresholds) []models.AnomalyCandidate {
+	var candidates []models.AnomalyCandidate
+	for start := 0; start < len(ratings); {
+		end := start
+		for end < len(ratings) && ratings[end].MovieID == ratings[start].MovieID {
+			end++
+		}
+		movieRatings := ratings[start:end]
+		start = end
+
+		flagged := make(map[int64]bool)
+		if burst := detectBurst(movieRatings, thresholds); burst != nil {
+			for _, id := range burst.RatingIDs {
+				flagged[id] = true
+			}
+			candidates = append(candidates, *burst)
+		}
+		if extreme := detectExtremeFirst(movieRatings, flagged, thresholds); extreme != nil {
+			candidates = append(candidates, *extreme)
+		}
+	}
+	return candidates
+}
+
+// detectBurst finds the largest group of new raters who rated the movie
+// within one burst window with scores within burstTolerance of each other.
+func detectBurst(ratings []models.RecentRating, thresholds models.AnomalyThresholds) *models.AnomalyCandidate {
+	var fresh []models.RecentRating
+	for _, rating := range ratings {
+		if rating.NewRater {
+			fresh = append(fresh, rating)
+		}
+	}
+	if thresholds.BurstMinRaters <= 0 || len(fresh) < thresholds.BurstMinRaters {
+		return nil
+	}
+
+	var best []models.RecentRating
+	var bestCenter float64
+	windowStart := 0
+	for windowEnd := range fresh {
+		for fresh[windowEnd].CreatedAt.Sub(fresh[windowStart].CreatedAt) > thresholds.BurstWindow {
+			windowStart++
+		}
+		window := fresh[windowStart : windowEnd+1]
+		if len(window) < thresholds.BurstMinRaters || len(window) <= len(best) {
+			continue
+		}
+
+		for _, center := range models.RatingScale {
+			var cluster []models.RecentRating
+			for _, rating := range window {
+				if rating.Rating >= center-burstTolerance && rating.Rating <= center+burstTolerance {
+					cluster = append(cluster, rating)
+				}
+			}
+			if len(cluster) > len(best) {
+				best, bestCenter = cluster, center
+			}
+		}
+	}
+	if len(best) < thresholds.BurstMinRaters {
+		return nil
+	}
+
+	ids := make([]int64, len(best))
+	for i, rating := range best {
+		ids[i] = rating.ID
+	}
+	return &models.AnomalyCandidate{
+		MovieID: best[0].MovieID,
+		Kind:    "burst",
+		Detail: fmt.Sprintf("%d new raters rated within %s, all scores within %.1f of %.1f",
+			len(best), best[len(best)-1].CreatedAt.Sub(best[0].CreatedAt).Round(time.Second), burstTolerance, bestCenter),
+		RatingIDs: ids,
+	}
+}
+
+// detectExtremeFirst flags raters whose first-ever rating is the lowest or
+// highest score, once enough of them have rated the movie.
+func detectExtremeFirst(ratings []models.RecentRating, skip map[int64]bool, thresholds models.AnomalyThresholds) *models.AnomalyCandidate {
+	lowest := models.RatingScale[0]
+	highest := models.RatingScale[len(models.RatingScale)-1]
+
+	var ids []int64
+	var low, high int
+	for _, rating := range ratings {
+		if skip[rating.ID] || !rating.NewRater || !rating.FirstAction {
+			continue
+		}
+		switch rating.Rating {
+		case lowest:
+			low++
+		case highest:
+			high++
+		default:
+			continue
+		}
+		ids = append(ids, rating.ID)
+	}
+	if thresholds.ExtremeMinRaters <= 0 || len(ids) < thresholds.ExtremeMinRaters {
+		return nil
+	}
+
+	return &models.AnomalyCandidate{
+		MovieID: ratings[0].MovieID,
+		Kind:    "extreme_first",
+		Detail: fmt.Sprintf("%d new raters opened with an extreme score (%d at %.1f, %d at %.1f)",
+			len(ids), low, lowest, high, highest),
+		RatingIDs: ids,
+	}
+}
+
+func (s *AnomalyService) ListAnomalies(status string, limit int, cursor string) (*models.RatingAnomalyPage, error) {
+	if status != "quarantined" && status != "released" {
+		return nil, fmt.Errorf("invalid status")
+	}
+
+	anomalies, nextCursor, err := s.ratingRepo.ListAnomalies(status, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if anomalies == nil {
+		anomalies = []models.RatingAnomaly{}
+	}
+
+	return &models.RatingAnomalyPage{
+		Items:      anomalies,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (s *AnomalyService) GetAnomaly(id int64) (*models.RatingAnomaly, error) {
+	return s.ratingRepo.GetAnomaly(id)
+}
+
+// ReleaseAnomaly puts an anomaly's ratings back into the aggregates.
+func (s *AnomalyService) ReleaseAnomaly(ctx context.Context, id int64, actor models.Actor) (*models.RatingAnomaly, error) {
+	anomaly, err := s.ratingRepo.ReleaseAnomaly(id, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, actor, AuditAnomalyRelease, fmt.Sprintf("anomaly:%d", anomaly.ID),
+		map[string]interface{}{"status": "quarantined"},
+		map[string]interface{}{"status": anomaly.Status, "releasedBy": anomaly.ReleasedBy})
+
+	return anomaly, nil
+}
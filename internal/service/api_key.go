@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"robin-camp/internal/auth"
+	"robin-camp/internal/logging"
+	"robin-camp/internal/models"
+	"robin-camp/internal/repository"
+)
+
+// APIKeyService mints, revokes and verifies scoped API keys. Keys look like
+// "rck_<prefix>_<secret>"; the prefix locates the row and only a SHA-256
+// hash of the whole key is stored.
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	audit      *AuditService
+}
+
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, audit *AuditService) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo, audit: audit}
+}
+
+// CreateKey mints a key on behalf of creator, who may only grant scopes
+// they hold themselves.
+func (s *APIKeyService) CreateKey(ctx context.Context, input models.APIKeyCreate, creator *auth.Claims, actor models.Actor) (*models.APIKeyCreated, error) {
+	scopes := make([]string, 0, len(input.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range input.Scopes {
+		if !auth.IsScope(scope) {
+			return nil, fmt.Errorf("unknown scope")
+		}
+		if !creator.HasScope(scope) {
+			return nil, fmt.Errorf("scope not held")
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expiry in the past")
+	}
+
+	prefix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := auth.APIKeyPrefix + prefix + "_" + secret
+
+	key := models.APIKey{
+		Name:      input.Name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+		CreatedBy: creator.Subject,
+	}
+	if err := s.apiKeyRepo.Create(&key, hashAPIKey(plaintext)); err != nil {
+		return nil, err
+	}
+
+	// The plaintext key is never written to the audit log
+	s.audit.Record(ctx, actor, AuditAPIKeyCreate, fmt.Sprintf("api_key:%d", key.ID), nil, key)
+
+	return &models.APIKeyCreated{APIKey: key, Key: plaintext}, nil
+}
+
+func (s *APIKeyService) ListKeys() (*models.APIKeyList, error) {
+	keys, err := s.apiKeyRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	return &models.APIKeyList{Items: keys}, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int64, actor models.Actor) error {
+	revoked, err := s.apiKeyRepo.Revoke(id)
+	if err != nil {
+		return err
+	}
+	if revoked == nil {
+		return fmt.Errorf("api key not found")
+	}
+
+	before := *revoked
+	before.RevokedAt = nil
+	s.audit.Record(ctx, actor, AuditAPIKeyRevoke, fmt.Sprintf("api_key:%d", id), before, revoked)
+
+	return nil
+}
+
+// VerifyAPIKey implements auth.APIKeyVerifier.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, plaintext string) (*auth.Claims, error) {
+	rest := strings.TrimPrefix(plaintext, auth.APIKeyPrefix)
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	key, secretHash, err := s.apiKeyRepo.GetByPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(hashAPIKey(plaintext)), []byte(secretHash)) != 1 {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID); err != nil {
+		logging.FromContext(ctx).Warn("failed to record api key use", "error", err)
+	}
+
+	return &auth.Claims{
+		Subject: "apikey:" + key.Prefix,
+		Scopes:  key.Scopes,
+	}, nil
+}
+
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"robin-camp/internal/logging"
+	"robin-camp/internal/models"
+	"robin-camp/internal/repository"
+)
+
+// Audit actions.
+const (
+	AuditMovieCreate     = "movie.create"
+	AuditReviewApprove   = "review.approve"
+	AuditReviewReject    = "review.reject"
+	AuditAnomalyRelease  = "anomaly.release"
+	AuditAPIKeyCreate    = "api_key.create"
+	AuditAPIKeyRevoke    = "api_key.revoke"
+	AuditRaterTokenIssue = "rater_token.issue"
+)
+
+// AuditService appends administrative writes to the audit log.
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record logs that actor performed action on target, storing the fields
+// that differ between before and after. Either may be nil. It runs after
+// the change has committed, so a failure is logged rather than returned.
+func (s *AuditService) Record(ctx context.Context, actor models.Actor, action, target string, before, after interface{}) {
+	entry := &models.AuditEntry{
+		Actor:   actor.ID,
+		Action:  action,
+		Target:  target,
+		Changes: diffFields(before, after),
+	}
+	if actor.RequestID != "" {
+		entry.RequestID = &actor.RequestID
+	}
+	if actor.ClientIP != "" {
+		entry.ClientIP = &actor.ClientIP
+	}
+
+	if err := s.auditRepo.Insert(ctx, entry); err != nil {
+		logging.FromContext(ctx).Error("failed to record audit entry",
+			"action", action, "target", target, "actor", actor.ID, "error", err)
+	}
+}
+
+func (s *AuditService) ListAudit(filters map[string]interface{}, limit int, cursor string) (*models.AuditPage, error) {
+	entries, nextCursor, err := s.auditRepo.List(filters, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+
+	return &models.AuditPage{
+		Items:      entries,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// diffFields compares the JSON forms of before and after field by field.
+func diffFields(before, after interface{}) map[string]models.AuditChange {
+	b, a := jsonFields(before), jsonFields(after)
+
+	changes := make(map[string]models.AuditChange)
+	for field, value := range a {
+		if old, ok := b[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = models.AuditChange{Before: b[field], After: value}
+		}
+	}
+	for field, value := range b {
+		if _, ok := a[field]; !ok {
+			changes[field] = models.AuditChange{Before: value}
+		}
+	}
+	return changes
+}
+
+func jsonFields(v interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
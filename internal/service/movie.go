@@ -1,30 +1,55 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"math"
 	"time"
 
 	"robin-camp/internal/client"
+	"robin-camp/internal/logging"
+	"robin-camp/internal/metrics"
 	"robin-camp/internal/models"
 	"robin-camp/internal/repository"
+	"robin-camp/internal/tracing"
+	"robin-camp/internal/ulid"
 )
 
 type MovieService struct {
-	repo            *repository.MovieRepository
-	boxOfficeClient *client.BoxOfficeClient
+	repo              *repository.MovieRepository
+	boxOfficeClient   *client.BoxOfficeClient
+	similarityWeights models.SimilarityWeights
+	audit             *AuditService
 }
 
-func NewMovieService(repo *repository.MovieRepository, boxOfficeClient *client.BoxOfficeClient) *MovieService {
+func NewMovieService(repo *repository.MovieRepository, boxOfficeClient *client.BoxOfficeClient, similarityWeights models.SimilarityWeights, audit *AuditService) *MovieService {
 	return &MovieService{
-		repo:            repo,
-		boxOfficeClient: boxOfficeClient,
+		repo:              repo,
+		boxOfficeClient:   boxOfficeClient,
+		similarityWeights: similarityWeights,
+		audit:             audit,
 	}
 }
 
-func (s *MovieService) CreateMovie(req *models.MovieCreate) (*models.Movie, error) {
-	// Generate movie ID
-	movieID := fmt.Sprintf("m_%d", time.Now().UnixNano())
+func (s *MovieService) CreateMovie(ctx context.Context, req *models.MovieCreate, actor models.Actor) (*models.Movie, error) {
+	ctx, span := tracing.Start(ctx, "MovieService.CreateMovie", tracing.KindInternal,
+		tracing.String("movie.title", req.Title))
+	defer span.End()
+
+	// The release year tells remakes apart and narrows the box office lookup
+	released, err := time.Parse("2006-01-02", req.ReleaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release date")
+	}
+
+	// Generate movie ID; ULIDs sort by creation time and do not collide
+	// across replicas
+	id, err := ulid.New()
+	if err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("failed to create movie: %w", err)
+	}
+	movieID := "m_" + id
 
 	// Create movie object
 	movie := &models.Movie{
@@ -39,10 +64,12 @@ func (s *MovieService) CreateMovie(req *models.MovieCreate) (*models.Movie, erro
 
 	// Try to fetch box office data
 	var boxOffice *models.BoxOffice
-	boxOfficeResp, err := s.boxOfficeClient.GetBoxOffice(req.Title)
+	metrics.BoxOfficeEnrichmentInFlight.Inc()
+	boxOfficeResp, err := s.boxOfficeClient.GetBoxOffice(ctx, req.Title, released.Year())
+	metrics.BoxOfficeEnrichmentInFlight.Dec()
 	if err != nil {
 		// Log error but don't fail the creation
-		log.Printf("Failed to fetch box office data for '%s': %v", req.Title, err)
+		logging.FromContext(ctx).Warn("failed to fetch box office data", "title", req.Title, "error", err)
 	} else {
 		// Merge box office data
 		boxOffice = &models.BoxOffice{
@@ -68,18 +95,30 @@ func (s *MovieService) CreateMovie(req *models.MovieCreate) (*models.Movie, erro
 	}
 
 	// Save to database
-	if err := s.repo.Create(movie, boxOffice); err != nil {
+	if err := s.repo.Create(ctx, movie, boxOffice); err != nil {
+		if err.Error() == "movie already exists" {
+			return nil, err
+		}
+		span.SetError(err)
 		return nil, fmt.Errorf("failed to create movie: %w", err)
 	}
 
 	// Set box office in response
 	movie.BoxOffice = boxOffice
 
+	s.audit.Record(ctx, actor, AuditMovieCreate, "movie:"+movie.ID, nil, movie)
+
 	return movie, nil
 }
 
-func (s *MovieService) GetMovieByTitle(title string) (*models.Movie, error) {
-	return s.repo.GetByTitle(title)
+// GetMovie returns the movie ref names, or nil.
+func (s *MovieService) GetMovie(ref models.MovieRef) (*models.Movie, error) {
+	return findMovie(s.repo.FindByRef, ref)
+}
+
+// GetMovieByID returns the movie with the given id, or nil.
+func (s *MovieService) GetMovieByID(id string) (*models.Movie, error) {
+	return s.repo.GetByID(id)
 }
 
 func (s *MovieService) ListMovies(filters map[string]interface{}, limit int, cursor string) (*models.MoviePage, error) {
@@ -93,3 +132,89 @@ func (s *MovieService) ListMovies(filters map[string]interface{}, limit int, cur
 		NextCursor: nextCursor,
 	}, nil
 }
+
+func (s *MovieService) SimilarMovies(ref models.MovieRef, limit int) (*models.SimilarMovies, error) {
+	// Check if movie exists
+	movie, err := findMovie(s.repo.FindByRef, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, fmt.Errorf("movie not found")
+	}
+
+	matches, err := s.repo.FindSimilar(movie.ID, s.similarityWeights, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SimilarMovies{Title: movie.Title, Items: []models.SimilarMovie{}}
+	for _, m := range matches {
+		result.Items = append(result.Items, models.SimilarMovie{
+			Movie:       m.Movie,
+			Score:       math.Round(m.Score*1000) / 1000,
+			Explanation: explainSimilarity(m),
+		})
+	}
+
+	return result, nil
+}
+
+// explainSimilarity lists the shared signals of a match, strongest
+// collaborative signal first.
+func explainSimilarity(m models.SimilarityMatch) []string {
+	var reasons []string
+	if m.CoRating > 0 {
+		reasons = append(reasons, "liked by similar raters")
+	}
+	if m.SameGenre {
+		reasons = append(reasons, "same genre")
+	}
+	if m.SameDistributor {
+		reasons = append(reasons, "same distributor")
+	}
+	if m.SameEra {
+		reasons = append(reasons, "released in the same decade")
+	}
+	if m.SameMPARating {
+		reasons = append(reasons, "same MPA rating")
+	}
+	if m.SameBudgetTier {
+		reasons = append(reasons, "similar budget")
+	}
+	return reasons
+}
+
+// AmbiguousMovieError reports a title shared by movies from different
+// release years; Candidates are ordered by release date.
+type AmbiguousMovieError struct {
+	Title      string
+	Candidates []models.Movie
+}
+
+func (e *AmbiguousMovieError) Error() string {
+	return fmt.Sprintf("%q matches %d movies", e.Title, len(e.Candidates))
+}
+
+// findMovie resolves ref with find, one of MovieRepository's FindByRef
+// methods. An exact slug match wins over title matches, so a slug always
+// names one movie; otherwise the title must name exactly one movie, or the
+// result is an *AmbiguousMovieError. It returns nil when nothing matches.
+func findMovie(find func(models.MovieRef) ([]models.Movie, error), ref models.MovieRef) (*models.Movie, error) {
+	movies, err := find(ref)
+	if err != nil {
+		return nil, err
+	}
+	for i := range movies {
+		if movies[i].Slug == ref.Ref {
+			return &movies[i], nil
+		}
+	}
+	switch len(movies) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &movies[0], nil
+	}
+	return nil, &AmbiguousMovieError{Title: ref.Ref, Candidates: movies}
+}
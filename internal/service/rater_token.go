@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"robin-camp/internal/auth"
+	"robin-camp/internal/models"
+)
+
+// RaterTokenService issues signed rater tokens, making the token's subject
+// the only way to act as a rater when token mode is enabled.
+type RaterTokenService struct {
+	keyring *auth.Keyring
+	audit   *AuditService
+}
+
+func NewRaterTokenService(keyring *auth.Keyring, audit *AuditService) *RaterTokenService {
+	return &RaterTokenService{keyring: keyring, audit: audit}
+}
+
+// IssueToken signs a token for raterID, or for a newly generated rater id
+// when raterID is empty. Tokens issued by an admin (a non-nil actor) are
+// audited; anonymous self-registration is not.
+func (s *RaterTokenService) IssueToken(ctx context.Context, raterID string, actor *models.Actor) (*models.RaterToken, error) {
+	if raterID == "" {
+		id, err := newRaterID()
+		if err != nil {
+			return nil, err
+		}
+		raterID = id
+	}
+
+	token, expiresAt, err := s.keyring.Issue(raterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue rater token: %w", err)
+	}
+
+	if actor != nil {
+		s.audit.Record(ctx, *actor, AuditRaterTokenIssue, "rater:"+raterID, nil,
+			map[string]interface{}{"expiresAt": expiresAt})
+	}
+
+	return &models.RaterToken{
+		RaterID:   raterID,
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyToken returns the rater id of a valid token.
+func (s *RaterTokenService) VerifyToken(token string) (string, error) {
+	claims, err := s.keyring.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
+
+func newRaterID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate rater id: %w", err)
+	}
+	return "r_" + hex.EncodeToString(b), nil
+}
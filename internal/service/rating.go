@@ -3,25 +3,33 @@ package service
 import (
 	"fmt"
 
+	"robin-camp/internal/metrics"
 	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
 	"robin-camp/internal/repository"
 )
 
 type RatingService struct {
-	movieRepo  *repository.MovieRepository
-	ratingRepo *repository.RatingRepository
+	movieRepo   *repository.MovieRepository
+	ratingRepo  *repository.RatingRepository
+	prior       models.RatingPrior
+	topMinCount int
+	moderator   *moderation.Pipeline
 }
 
-func NewRatingService(movieRepo *repository.MovieRepository, ratingRepo *repository.RatingRepository) *RatingService {
+func NewRatingService(movieRepo *repository.MovieRepository, ratingRepo *repository.RatingRepository, prior models.RatingPrior, topMinCount int, moderator *moderation.Pipeline) *RatingService {
 	return &RatingService{
-		movieRepo:  movieRepo,
-		ratingRepo: ratingRepo,
+		movieRepo:   movieRepo,
+		ratingRepo:  ratingRepo,
+		prior:       prior,
+		topMinCount: topMinCount,
+		moderator:   moderator,
 	}
 }
 
-func (s *RatingService) SubmitRating(title, raterID string, rating float64) (*models.Rating, bool, error) {
+func (s *RatingService) SubmitRating(ref models.MovieRef, raterID string, rating float64, review *models.ReviewInput) (*models.Rating, bool, error) {
 	// Check if movie exists
-	movie, err := s.movieRepo.GetByTitle(title)
+	movie, err := findMovie(s.movieRepo.FindByRefFromPrimary, ref)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to get movie: %w", err)
 	}
@@ -29,22 +37,60 @@ func (s *RatingService) SubmitRating(title, raterID string, rating float64) (*mo
 		return nil, false, fmt.Errorf("movie not found")
 	}
 
+	// Run new review text through the content filters
+	if review != nil && review.Body != nil {
+		text := *review.Body
+		if review.Headline != nil {
+			text = *review.Headline + "\n" + text
+		}
+		review.Status, review.ModerationReason = s.moderator.Evaluate(text)
+	}
+
 	// Upsert rating
-	isNew, err := s.ratingRepo.Upsert(movie.ID, raterID, rating)
+	isNew, stored, err := s.ratingRepo.Upsert(movie.ID, raterID, rating, review)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to submit rating: %w", err)
 	}
 
+	result := "updated"
+	if isNew {
+		result = "new"
+	}
+	metrics.RatingSubmissions.Inc(result)
+
 	return &models.Rating{
-		MovieTitle: title,
+		MovieTitle: movie.Title,
 		RaterID:    raterID,
 		Rating:     rating,
+		Review:     stored,
+		MovieSlug:  movie.Slug,
 	}, isNew, nil
 }
 
-func (s *RatingService) GetRatingAggregate(title string) (*models.RatingAggregate, error) {
+func (s *RatingService) DeleteRating(ref models.MovieRef, raterID string) error {
 	// Check if movie exists
-	movie, err := s.movieRepo.GetByTitle(title)
+	movie, err := findMovie(s.movieRepo.FindByRefFromPrimary, ref)
+	if err != nil {
+		return fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return fmt.Errorf("movie not found")
+	}
+
+	deleted, err := s.ratingRepo.Delete(movie.ID, raterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rating: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("rating not found")
+	}
+
+	return nil
+}
+
+func (s *RatingService) GetRatingAggregate(ref models.MovieRef, detail bool) (*models.RatingAggregate, error) {
+	// Check if movie exists
+	movie, err := findMovie(s.movieRepo.FindByRef, ref)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movie: %w", err)
 	}
@@ -53,5 +99,79 @@ func (s *RatingService) GetRatingAggregate(title string) (*models.RatingAggregat
 	}
 
 	// Get aggregate
-	return s.ratingRepo.GetAggregate(movie.ID)
+	if detail {
+		return s.ratingRepo.GetAggregateDetail(movie.ID, s.prior)
+	}
+	return s.ratingRepo.GetAggregate(movie.ID, s.prior)
+}
+
+func (s *RatingService) ListRaterRatings(raterID, sort string, limit int, cursor string) (*models.RaterRatingPage, error) {
+	ratings, nextCursor, err := s.ratingRepo.ListByRater(raterID, sort, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.ratingRepo.GetRaterStats(raterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ratings == nil {
+		ratings = []models.RaterRating{}
+	}
+
+	return &models.RaterRatingPage{
+		RaterID:    raterID,
+		Items:      ratings,
+		NextCursor: nextCursor,
+		Stats:      *stats,
+	}, nil
+}
+
+// TopRated returns the leaderboard by weighted rating. A minCount of 0
+// falls back to the configured threshold.
+func (s *RatingService) TopRated(filters map[string]interface{}, minCount, limit int) (*models.TopMovies, error) {
+	if minCount <= 0 {
+		minCount = s.topMinCount
+	}
+	if minCount < 1 {
+		minCount = 1
+	}
+
+	top, err := s.ratingRepo.TopRated(filters, s.prior, minCount, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if top == nil {
+		top = []models.TopMovie{}
+	}
+
+	return &models.TopMovies{Items: top}, nil
+}
+
+func (s *RatingService) ListReviews(ref models.MovieRef, sort string, limit int, cursor string) (*models.ReviewPage, error) {
+	// Check if movie exists
+	movie, err := findMovie(s.movieRepo.FindByRef, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, fmt.Errorf("movie not found")
+	}
+
+	reviews, nextCursor, err := s.ratingRepo.ListReviews(movie.ID, sort, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviews == nil {
+		reviews = []models.Review{}
+	}
+
+	return &models.ReviewPage{
+		MovieTitle: movie.Title,
+		Items:      reviews,
+		NextCursor: nextCursor,
+	}, nil
 }
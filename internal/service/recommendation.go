@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/repository"
+)
+
+// RecommendationService implements item-item collaborative filtering. A
+// background job computes adjusted-cosine similarities between movies and
+// stores each movie's top-K neighbours; recommendations for a rater are
+// then scored from the neighbours of the movies they have rated.
+type RecommendationService struct {
+	movieRepo    *repository.MovieRepository
+	ratingRepo   *repository.RatingRepository
+	neighborRepo *repository.NeighborRepository
+	interval     time.Duration
+	neighbors    int
+	minCoRaters  int
+}
+
+func NewRecommendationService(
+	movieRepo *repository.MovieRepository,
+	ratingRepo *repository.RatingRepository,
+	neighborRepo *repository.NeighborRepository,
+	interval time.Duration,
+	neighbors, minCoRaters int,
+) *RecommendationService {
+	return &RecommendationService{
+		movieRepo:    movieRepo,
+		ratingRepo:   ratingRepo,
+		neighborRepo: neighborRepo,
+		interval:     interval,
+		neighbors:    neighbors,
+		minCoRaters:  minCoRaters,
+	}
+}
+
+// Start rebuilds the neighbour table immediately and then on each interval
+// until ctx is cancelled.
+func (s *RecommendationService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.RebuildNeighbors(); err != nil {
+				slog.Error("failed to rebuild movie neighbors", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *RecommendationService) RebuildNeighbors() error {
+	entries, err := s.ratingRepo.ListMatrix()
+	if err != nil {
+		return err
+	}
+
+	neighbors := computeNeighbors(entries, s.neighbors, s.minCoRaters)
+	if err := s.neighborRepo.ReplaceAll(neighbors); err != nil {
+		return err
+	}
+
+	slog.Info("rebuilt movie neighbors", "pairs", len(neighbors), "ratings", len(entries))
+	return nil
+}
+
+type moviePair struct {
+	a, b string
+}
+
+type pairSums struct {
+	dot, normA, normB float64
+	coRaters          int
+}
+
+// computeNeighbors returns, for every movie, up to k other movies with the
+// highest positive adjusted-cosine similarity. Each rater's ratings are
+// centred on that rater's mean, so generous and harsh raters compare fairly.
+// Pairs co-rated by fewer than minCoRaters raters are ignored.
+func computeNeighbors(entries []models.RatingEntry, k, minCoRaters int) []models.MovieNeighbor {
+	byRater := make(map[string][]models.RatingEntry)
+	for _, e := range entries {
+		byRater[e.RaterID] = append(byRater[e.RaterID], e)
+	}
+
+	sums := make(map[moviePair]*pairSums)
+	for _, ratings := range byRater {
+		if len(ratings) < 2 {
+			continue
+		}
+
+		var mean float64
+		for _, e := range ratings {
+			mean += e.Rating
+		}
+		mean /= float64(len(ratings))
+
+		for i := 0; i < len(ratings); i++ {
+			for j := i + 1; j < len(ratings); j++ {
+				a, b := ratings[i], ratings[j]
+				if a.MovieID > b.MovieID {
+					a, b = b, a
+				}
+				da, db := a.Rating-mean, b.Rating-mean
+
+				key := moviePair{a: a.MovieID, b: b.MovieID}
+				acc, ok := sums[key]
+				if !ok {
+					acc = &pairSums{}
+					sums[key] = acc
+				}
+				acc.dot += da * db
+				acc.normA += da * da
+				acc.normB += db * db
+				acc.coRaters++
+			}
+		}
+	}
+
+	byMovie := make(map[string][]models.MovieNeighbor)
+	for key, acc := range sums {
+		if acc.coRaters < minCoRaters || acc.normA == 0 || acc.normB == 0 {
+			continue
+		}
+		sim := acc.dot / (math.Sqrt(acc.normA) * math.Sqrt(acc.normB))
+		if sim <= 0 {
+			continue
+		}
+
+		byMovie[key.a] = append(byMovie[key.a], models.MovieNeighbor{
+			MovieID: key.a, NeighborID: key.b, Similarity: sim, CoRaters: acc.coRaters,
+		})
+		byMovie[key.b] = append(byMovie[key.b], models.MovieNeighbor{
+			MovieID: key.b, NeighborID: key.a, Similarity: sim, CoRaters: acc.coRaters,
+		})
+	}
+
+	var result []models.MovieNeighbor
+	for _, list := range byMovie {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Similarity != list[j].Similarity {
+				return list[i].Similarity > list[j].Similarity
+			}
+			return list[i].NeighborID < list[j].NeighborID
+		})
+		if len(list) > k {
+			list = list[:k]
+		}
+		result = append(result, list...)
+	}
+
+	return result
+}
+
+type candidateScore struct {
+	movieID       string
+	weightedDelta float64
+	simSum        float64
+	basedOn       int
+}
+
+// GetRecommendations predicts ratings for movies the rater has not rated,
+// as the rater's mean plus the similarity-weighted average of their
+// centred ratings on each candidate's neighbours.
+func (s *RecommendationService) GetRecommendations(raterID string, limit int) (*models.RecommendationList, error) {
+	result := &models.RecommendationList{RaterID: raterID, Items: []models.Recommendation{}}
+
+	vector, err := s.ratingRepo.GetRaterVector(raterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(vector) == 0 {
+		return result, nil
+	}
+
+	var mean float64
+	rated := make([]string, 0, len(vector))
+	for movieID, rating := range vector {
+		mean += rating
+		rated = append(rated, movieID)
+	}
+	mean /= float64(len(vector))
+
+	neighbors, err := s.neighborRepo.ListForMovies(rated)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]*candidateScore)
+	for _, n := range neighbors {
+		if _, seen := vector[n.NeighborID]; seen {
+			continue
+		}
+		c, ok := candidates[n.NeighborID]
+		if !ok {
+			c = &candidateScore{movieID: n.NeighborID}
+			candidates[n.NeighborID] = c
+		}
+		c.weightedDelta += n.Similarity * (vector[n.MovieID] - mean)
+		c.simSum += n.Similarity
+		c.basedOn++
+	}
+
+	scored := make([]candidateScore, 0, len(candidates))
+	for _, c := range candidates {
+		scored = append(scored, *c)
+	}
+	predict := func(c candidateScore) float64 {
+		p := mean + c.weightedDelta/c.simSum
+		return math.Max(0.5, math.Min(5.0, p))
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		pi, pj := predict(scored[i]), predict(scored[j])
+		if pi != pj {
+			return pi > pj
+		}
+		if scored[i].simSum != scored[j].simSum {
+			return scored[i].simSum > scored[j].simSum
+		}
+		return scored[i].movieID < scored[j].movieID
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	if len(scored) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, len(scored))
+	for i, c := range scored {
+		ids[i] = c.movieID
+	}
+	movies, err := s.movieRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range scored {
+		movie, ok := movies[c.movieID]
+		if !ok {
+			continue
+		}
+		result.Items = append(result.Items, models.Recommendation{
+			Movie:           movie,
+			PredictedRating: math.Round(predict(c)*100) / 100,
+			BasedOn:         c.basedOn,
+		})
+	}
+
+	return result, nil
+}
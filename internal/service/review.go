@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/moderation"
+	"robin-camp/internal/repository"
+)
+
+// ReviewService handles community feedback on reviews (helpfulness votes,
+// reports and the queue of reported reviews) and their moderation.
+type ReviewService struct {
+	movieRepo       *repository.MovieRepository
+	ratingRepo      *repository.RatingRepository
+	reportThreshold int
+	audit           *AuditService
+}
+
+func NewReviewService(movieRepo *repository.MovieRepository, ratingRepo *repository.RatingRepository, reportThreshold int, audit *AuditService) *ReviewService {
+	return &ReviewService{
+		movieRepo:       movieRepo,
+		ratingRepo:      ratingRepo,
+		reportThreshold: reportThreshold,
+		audit:           audit,
+	}
+}
+
+func (s *ReviewService) VoteReview(ref models.MovieRef, reviewerID, voterID string, helpful bool) (*models.ReviewVote, bool, error) {
+	if reviewerID == voterID {
+		return nil, false, fmt.Errorf("cannot vote on own review")
+	}
+
+	// Check if movie exists
+	movie, err := findMovie(s.movieRepo.FindByRefFromPrimary, ref)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, false, fmt.Errorf("movie not found")
+	}
+
+	isNew, helpfulCount, unhelpfulCount, err := s.ratingRepo.VoteReview(movie.ID, reviewerID, voterID, helpful)
+	if err != nil {
+		return nil, false, err
+	}
+
+	vote := "unhelpful"
+	if helpful {
+		vote = "helpful"
+	}
+
+	return &models.ReviewVote{
+		MovieTitle:     movie.Title,
+		ReviewerID:     reviewerID,
+		VoterID:        voterID,
+		Vote:           vote,
+		HelpfulCount:   helpfulCount,
+		UnhelpfulCount: unhelpfulCount,
+	}, isNew, nil
+}
+
+func (s *ReviewService) ReportReview(ref models.MovieRef, reviewerID, reporterID, reason string, details *string) (*models.ReviewReport, bool, error) {
+	// Check if movie exists
+	movie, err := findMovie(s.movieRepo.FindByRefFromPrimary, ref)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, false, fmt.Errorf("movie not found")
+	}
+
+	report, isNew, err := s.ratingRepo.ReportReview(movie.ID, reviewerID, reporterID, reason, details, s.reportThreshold)
+	if err != nil {
+		return nil, false, err
+	}
+	report.MovieTitle = movie.Title
+
+	return report, isNew, nil
+}
+
+func (s *ReviewService) ListReportedReviews(limit int, cursor string) (*models.ReportedReviewPage, error) {
+	reported, nextCursor, err := s.ratingRepo.ListReportedReviews(limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if reported == nil {
+		reported = []models.ReportedReview{}
+	}
+
+	return &models.ReportedReviewPage{
+		Items:      reported,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// ModerateReview approves or rejects a review on behalf of actor.
+func (s *ReviewService) ModerateReview(ctx context.Context, ref models.MovieRef, reviewerID string, actor models.Actor, toStatus, reason string) (*models.ModerationEvent, error) {
+	// Check if movie exists
+	movie, err := findMovie(s.movieRepo.FindByRefFromPrimary, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, fmt.Errorf("movie not found")
+	}
+
+	event, err := s.ratingRepo.ModerateReview(movie.ID, reviewerID, actor.ID, toStatus, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	action := AuditReviewReject
+	if toStatus == moderation.StatusApproved {
+		action = AuditReviewApprove
+	}
+	s.audit.Record(ctx, actor, action, "review:"+movie.ID+":"+reviewerID,
+		map[string]interface{}{"status": event.FromStatus},
+		map[string]interface{}{"status": event.ToStatus, "reason": event.Reason})
+
+	return event, nil
+}
+
+func (s *ReviewService) ListModerationQueue(status string, limit int, cursor string) (*models.ModerationQueue, error) {
+	if !moderation.IsStatus(status) {
+		return nil, fmt.Errorf("invalid status")
+	}
+
+	items, nextCursor, err := s.ratingRepo.ListModerationQueue(status, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if items == nil {
+		items = []models.ModerationItem{}
+	}
+
+	return &models.ModerationQueue{
+		Items:      items,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (s *ReviewService) GetModerationHistory(ref models.MovieRef, reviewerID string) (*models.ModerationHistory, error) {
+	// Check if movie exists
+	movie, err := findMovie(s.movieRepo.FindByRef, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+	if movie == nil {
+		return nil, fmt.Errorf("movie not found")
+	}
+
+	status, events, err := s.ratingRepo.GetModerationHistory(movie.ID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ModerationHistory{
+		MovieTitle: movie.Title,
+		ReviewerID: reviewerID,
+		Status:     status,
+		Events:     events,
+	}, nil
+}
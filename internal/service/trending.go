@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"robin-camp/internal/models"
+	"robin-camp/internal/repository"
+)
+
+// trendingWindows maps the accepted window names to Postgres intervals.
+var trendingWindows = map[string]string{
+	"24h": "24 hours",
+	"7d":  "7 days",
+	"30d": "30 days",
+}
+
+// trendingDepth is how many movies are cached per window; requests are
+// served by slicing this list.
+const trendingDepth = 100
+
+type trendingKey struct {
+	window   string
+	weighted bool
+}
+
+// TrendingService serves trending rankings from an in-process cache that a
+// background loop refreshes on a fixed interval, so requests never hit the
+// database once the cache is warm.
+type TrendingService struct {
+	ratingRepo *repository.RatingRepository
+	interval   time.Duration
+
+	mu    sync.RWMutex
+	cache map[trendingKey]*models.TrendingMovies
+}
+
+func NewTrendingService(ratingRepo *repository.RatingRepository, interval time.Duration) *TrendingService {
+	return &TrendingService{
+		ratingRepo: ratingRepo,
+		interval:   interval,
+		cache:      make(map[trendingKey]*models.TrendingMovies),
+	}
+}
+
+// Start refreshes every window immediately and then on each interval until
+// ctx is cancelled.
+func (s *TrendingService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.refreshAll()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *TrendingService) refreshAll() {
+	for window := range trendingWindows {
+		for _, weighted := range []bool{false, true} {
+			if _, err := s.refresh(trendingKey{window: window, weighted: weighted}); err != nil {
+				slog.Error("failed to refresh trending movies", "window", window, "error", err)
+			}
+		}
+	}
+}
+
+func (s *TrendingService) refresh(key trendingKey) (*models.TrendingMovies, error) {
+	items, err := s.ratingRepo.Trending(trendingWindows[key.window], key.weighted, trendingDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	if items == nil {
+		items = []models.TrendingMovie{}
+	}
+
+	result := &models.TrendingMovies{
+		Window:      key.window,
+		Weighted:    key.weighted,
+		GeneratedAt: time.Now().UTC(),
+		Items:       items,
+	}
+
+	s.mu.Lock()
+	s.cache[key] = result
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// GetTrending returns the top limit movies for window. A cold cache is
+// filled synchronously.
+func (s *TrendingService) GetTrending(window string, weighted bool, limit int) (*models.TrendingMovies, error) {
+	if _, ok := trendingWindows[window]; !ok {
+		return nil, fmt.Errorf("invalid window")
+	}
+
+	key := trendingKey{window: window, weighted: weighted}
+
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		var err error
+		cached, err = s.refresh(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Copy the header so callers can't truncate the cached slice
+	result := *cached
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+	}
+
+	return &result, nil
+}
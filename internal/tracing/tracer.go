@@ -0,0 +1,156 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	queueSize     = 2048
+	batchSize     = 512
+	flushInterval = 5 * time.Second
+)
+
+// Exporter sends finished spans somewhere.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Tracer creates spans and exports the sampled ones in batches.
+type Tracer struct {
+	exporter Exporter
+	// threshold is compared with the low 64 bits of a new trace id; root
+	// spans below it are sampled
+	threshold atomic.Uint64
+	always    atomic.Bool
+	queue     chan SpanData
+	dropped   atomic.Int64
+}
+
+// NewTracer samples sampleRatio (0 to 1) of new traces; spans with a
+// parent follow the parent's decision.
+func NewTracer(exporter Exporter, sampleRatio float64) *Tracer {
+	t := &Tracer{
+		exporter: exporter,
+		queue:    make(chan SpanData, queueSize),
+	}
+	t.SetSampleRatio(sampleRatio)
+	return t
+}
+
+// SetSampleRatio changes the ratio of new traces sampled from now on.
+func (t *Tracer) SetSampleRatio(sampleRatio float64) {
+	switch {
+	case sampleRatio >= 1:
+		t.always.Store(true)
+	case sampleRatio > 0:
+		t.threshold.Store(uint64(sampleRatio * math.MaxUint64))
+		t.always.Store(false)
+	default:
+		t.threshold.Store(0)
+		t.always.Store(false)
+	}
+}
+
+func (t *Tracer) sample(id TraceID) bool {
+	return t.always.Load() || binary.BigEndian.Uint64(id[8:]) < t.threshold.Load()
+}
+
+func (t *Tracer) enqueue(span SpanData) {
+	select {
+	case t.queue <- span:
+	default:
+		// Never block the request path on a slow exporter
+		t.dropped.Add(1)
+	}
+}
+
+// Start exports queued spans in batches until ctx is cancelled, then
+// flushes what is left.
+func (t *Tracer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+
+		batch := make([]SpanData, 0, batchSize)
+		flush := func() {
+			if n := t.dropped.Swap(0); n > 0 {
+				slog.Warn("dropped spans, export queue full", "spans", n)
+			}
+			if len(batch) == 0 {
+				return
+			}
+			exportCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := t.exporter.Export(exportCtx, batch); err != nil {
+				slog.Error("failed to export spans", "spans", len(batch), "error", err)
+			}
+			cancel()
+			batch = batch[:0]
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				for {
+					select {
+					case span := <-t.queue:
+						batch = append(batch, span)
+					default:
+						flush()
+						return
+					}
+				}
+			case span := <-t.queue:
+				batch = append(batch, span)
+				if len(batch) >= batchSize {
+					flush()
+				}
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}()
+}
+
+var global atomic.Pointer[Tracer]
+
+// SetTracer installs the tracer used by Start. Until it is called, or
+// after SetTracer(nil), tracing is disabled and Start returns nil spans.
+func SetTracer(t *Tracer) {
+	global.Store(t)
+}
+
+// Start begins a span as a child of the span or remote parent in ctx, and
+// returns a context carrying it. End the span when the operation is done.
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t}
+	span.data = SpanData{
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: attrs,
+	}
+	if parent, ok := parentOf(ctx); ok {
+		span.data.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+		span.data.ParentSpanID = parent.SpanID
+	} else {
+		traceID := newTraceID()
+		span.data.SpanContext = SpanContext{TraceID: traceID, SpanID: newSpanID(), Sampled: t.sample(traceID)}
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Enabled reports whether a tracer is installed.
+func Enabled() bool {
+	return global.Load() != nil
+}
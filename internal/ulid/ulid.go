@@ -0,0 +1,91 @@
+// Package ulid generates ULIDs: 128-bit identifiers made of a 48-bit
+// millisecond timestamp and 80 random bits, written as 26 Crockford base32
+// characters so that they sort lexicographically by creation time.
+package ulid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Generator hands out ULIDs that increase strictly within a process: ids
+// created in the same millisecond reuse its random part incremented by one,
+// so they never collide locally and keep their order. Across processes the
+// 80 random bits make collisions negligible.
+type Generator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	lastRnd [10]byte
+}
+
+var defaultGenerator Generator
+
+// New returns a ULID for the current time from the process-wide generator.
+func New() (string, error) {
+	return defaultGenerator.New(time.Now())
+}
+
+// New returns a ULID for now. A clock that steps backwards keeps using the
+// last timestamp, so ids stay monotonic.
+func (g *Generator) New(now time.Time) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(now.UnixMilli())
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		if !increment(&g.lastRnd) {
+			// The random part overflowed; borrow the next millisecond
+			ms++
+			if _, err := rand.Read(g.lastRnd[:]); err != nil {
+				return "", fmt.Errorf("failed to generate ulid: %w", err)
+			}
+		}
+	} else if _, err := rand.Read(g.lastRnd[:]); err != nil {
+		return "", fmt.Errorf("failed to generate ulid: %w", err)
+	}
+	g.lastMs = ms
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], g.lastRnd[:])
+	return encode(id), nil
+}
+
+// increment adds one to b as a big-endian number, reporting false on
+// overflow.
+func increment(b *[10]byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode writes the 128 bits of id as 26 base32 characters, the first of
+// which carries only the top 3 bits.
+func encode(id [16]byte) string {
+	out := make([]byte, 26)
+	for i := range out {
+		// Bit offset of this character within a 130-bit, zero-padded value
+		offset := i*5 - 2
+		var v int
+		for bit := 0; bit < 5; bit++ {
+			pos := offset + bit
+			v <<= 1
+			if pos >= 0 && id[pos/8]&(0x80>>(pos%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = alphabet[v]
+	}
+	return string(out)
+}
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_movie_rating_stats_average;
+
+-- Drop tables
+DROP TABLE IF EXISTS movie_rating_stats;
@@ -0,0 +1,32 @@
+-- Create movie_rating_stats table (materialized per-movie rating aggregates,
+-- maintained by the application on every rating write)
+CREATE TABLE IF NOT EXISTS movie_rating_stats (
+    movie_id VARCHAR(50) PRIMARY KEY,
+    rating_sum DECIMAL(12,1) NOT NULL DEFAULT 0,
+    rating_count INTEGER NOT NULL DEFAULT 0,
+    -- bucket_counts[i] is the number of ratings equal to i * 0.5
+    bucket_counts INTEGER[] NOT NULL DEFAULT '{0,0,0,0,0,0,0,0,0,0}',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_rating_stats_average
+    ON movie_rating_stats ((rating_sum / NULLIF(rating_count, 0)));
+
+-- Backfill movies rated before the table existed
+INSERT INTO movie_rating_stats (movie_id, rating_sum, rating_count, bucket_counts)
+SELECT movie_id, SUM(rating), COUNT(*), ARRAY[
+    COUNT(*) FILTER (WHERE rating = 0.5),
+    COUNT(*) FILTER (WHERE rating = 1.0),
+    COUNT(*) FILTER (WHERE rating = 1.5),
+    COUNT(*) FILTER (WHERE rating = 2.0),
+    COUNT(*) FILTER (WHERE rating = 2.5),
+    COUNT(*) FILTER (WHERE rating = 3.0),
+    COUNT(*) FILTER (WHERE rating = 3.5),
+    COUNT(*) FILTER (WHERE rating = 4.0),
+    COUNT(*) FILTER (WHERE rating = 4.5),
+    COUNT(*) FILTER (WHERE rating = 5.0)
+]::INTEGER[]
+FROM ratings
+GROUP BY movie_id
+ON CONFLICT (movie_id) DO NOTHING;
@@ -0,0 +1,12 @@
+-- Create movie_neighbors table (top-K item-item similarities, rebuilt
+-- periodically by the recommendation job)
+CREATE TABLE IF NOT EXISTS movie_neighbors (
+    movie_id VARCHAR(50) NOT NULL,
+    neighbor_id VARCHAR(50) NOT NULL,
+    similarity DOUBLE PRECISION NOT NULL,
+    co_raters INTEGER NOT NULL,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (movie_id, neighbor_id),
+    FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    FOREIGN KEY (neighbor_id) REFERENCES movies(id) ON DELETE CASCADE
+);
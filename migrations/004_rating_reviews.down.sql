@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_ratings_reviews_helpful;
+DROP INDEX IF EXISTS idx_ratings_reviews_newest;
+
+-- Drop columns
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_helpful_count;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_updated_at;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_created_at;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_body;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_headline;
@@ -0,0 +1,13 @@
+-- Add optional written reviews to ratings
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_headline VARCHAR(120);
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_body TEXT;
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_created_at TIMESTAMP;
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_updated_at TIMESTAMP;
+-- Maintained by review helpfulness votes
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_helpful_count INTEGER NOT NULL DEFAULT 0;
+
+-- Create indexes for review listings
+CREATE INDEX IF NOT EXISTS idx_ratings_reviews_newest
+    ON ratings(movie_id, review_updated_at DESC, id DESC) WHERE review_body IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ratings_reviews_helpful
+    ON ratings(movie_id, review_helpful_count DESC, id DESC) WHERE review_body IS NOT NULL;
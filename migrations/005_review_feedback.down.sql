@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_review_reports_rating_id;
+DROP INDEX IF EXISTS idx_review_votes_voter_id;
+DROP INDEX IF EXISTS idx_ratings_reviews_reported;
+
+-- Drop tables
+DROP TABLE IF EXISTS review_reports;
+DROP TABLE IF EXISTS review_votes;
+
+-- Drop columns
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_report_count;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_unhelpful_count;
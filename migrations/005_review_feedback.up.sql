@@ -0,0 +1,32 @@
+-- Add vote and report counters to reviews
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_unhelpful_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_report_count INTEGER NOT NULL DEFAULT 0;
+
+-- Create review_votes table (one helpful/unhelpful vote per voter per review)
+CREATE TABLE IF NOT EXISTS review_votes (
+    rating_id INTEGER NOT NULL,
+    voter_id VARCHAR(100) NOT NULL,
+    helpful BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rating_id, voter_id),
+    FOREIGN KEY (rating_id) REFERENCES ratings(id) ON DELETE CASCADE
+);
+
+-- Create review_reports table (one report per reporter per review)
+CREATE TABLE IF NOT EXISTS review_reports (
+    id SERIAL PRIMARY KEY,
+    rating_id INTEGER NOT NULL,
+    reporter_id VARCHAR(100) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('spam', 'offensive', 'spoiler', 'off_topic', 'other')),
+    details VARCHAR(500),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (rating_id) REFERENCES ratings(id) ON DELETE CASCADE,
+    UNIQUE (rating_id, reporter_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_ratings_reviews_reported
+    ON ratings(review_report_count DESC, id DESC) WHERE review_report_count > 0;
+CREATE INDEX IF NOT EXISTS idx_review_votes_voter_id ON review_votes(voter_id);
+CREATE INDEX IF NOT EXISTS idx_review_reports_rating_id ON review_reports(rating_id);
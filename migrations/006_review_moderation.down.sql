@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_review_moderation_log_rating_id;
+DROP INDEX IF EXISTS idx_ratings_reviews_status;
+
+-- Drop tables
+DROP TABLE IF EXISTS review_moderation_log;
+
+-- Drop columns
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_status;
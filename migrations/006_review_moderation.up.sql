@@ -0,0 +1,21 @@
+-- Add moderation status to reviews; reviews written before moderation
+-- existed stay published
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_status VARCHAR(10) NOT NULL DEFAULT 'approved'
+    CHECK (review_status IN ('pending', 'approved', 'rejected', 'hidden'));
+
+-- Create review_moderation_log table (audit trail of every status change)
+CREATE TABLE IF NOT EXISTS review_moderation_log (
+    id SERIAL PRIMARY KEY,
+    rating_id INTEGER NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    from_status VARCHAR(10),
+    to_status VARCHAR(10) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (rating_id) REFERENCES ratings(id) ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_ratings_reviews_status
+    ON ratings(review_status, review_updated_at, id) WHERE review_body IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_review_moderation_log_rating_id ON review_moderation_log(rating_id);
@@ -0,0 +1,11 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_rating_anomalies_status;
+DROP INDEX IF EXISTS idx_ratings_anomaly_id;
+DROP INDEX IF EXISTS idx_ratings_created_at;
+
+-- Drop columns
+ALTER TABLE ratings DROP COLUMN IF EXISTS anomaly_id;
+ALTER TABLE ratings DROP COLUMN IF EXISTS quarantined;
+
+-- Drop tables
+DROP TABLE IF EXISTS rating_anomalies;
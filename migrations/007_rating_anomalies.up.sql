@@ -0,0 +1,23 @@
+-- Create rating_anomalies table (suspicious groups of ratings found by the detector)
+CREATE TABLE IF NOT EXISTS rating_anomalies (
+    id SERIAL PRIMARY KEY,
+    movie_id VARCHAR(50) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('burst', 'extreme_first')),
+    status VARCHAR(20) NOT NULL DEFAULT 'quarantined' CHECK (status IN ('quarantined', 'released')),
+    rating_count INTEGER NOT NULL,
+    detail TEXT NOT NULL,
+    detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP,
+    released_by VARCHAR(100),
+    FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+-- Quarantined ratings are kept but left out of movie_rating_stats; anomaly_id
+-- stays set after release so the detector does not flag them again
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS quarantined BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS anomaly_id INTEGER REFERENCES rating_anomalies(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_ratings_created_at ON ratings(created_at);
+CREATE INDEX IF NOT EXISTS idx_ratings_anomaly_id ON ratings(anomaly_id) WHERE anomaly_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rating_anomalies_status ON rating_anomalies(status, detected_at DESC, id DESC);
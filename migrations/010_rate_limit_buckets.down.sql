@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_rate_limit_buckets_full_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS rate_limit_buckets;
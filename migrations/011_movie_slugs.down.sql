@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_movies_slug;
+
+-- Drop columns
+ALTER TABLE movies DROP COLUMN IF EXISTS slug;
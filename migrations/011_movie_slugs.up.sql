@@ -0,0 +1,33 @@
+-- Add slug column (URL-safe movie handle, unique across movies)
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+
+-- Backfill slugs with the same rules as the service: lowercase ASCII letters
+-- and digits joined by dashes, at most 80 characters, "movie" when nothing
+-- is left, and the lowest free "-<n>" suffix for duplicates and for the
+-- reserved paths top, trending and id
+DO $$
+DECLARE
+    movie RECORD;
+    base TEXT;
+    candidate TEXT;
+    n INTEGER;
+BEGIN
+    FOR movie IN SELECT id, title FROM movies WHERE slug IS NULL ORDER BY created_at, id LOOP
+        base := rtrim(left(trim(both '-' from regexp_replace(lower(movie.title), '[^a-z0-9]+', '-', 'g')), 80), '-');
+        IF base = '' THEN
+            base := 'movie';
+        END IF;
+        candidate := base;
+        n := 1;
+        WHILE candidate IN ('top', 'trending', 'id') OR EXISTS (SELECT 1 FROM movies WHERE slug = candidate) LOOP
+            n := n + 1;
+            candidate := base || '-' || n;
+        END LOOP;
+        UPDATE movies SET slug = candidate WHERE id = movie.id;
+    END LOOP;
+END $$;
+
+ALTER TABLE movies ALTER COLUMN slug SET NOT NULL;
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_movies_slug ON movies(slug);
@@ -0,0 +1,10 @@
+-- Restore the unique title constraint (fails while remakes share a title)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'movies_title_key') THEN
+        ALTER TABLE movies ADD CONSTRAINT movies_title_key UNIQUE (title);
+    END IF;
+END $$;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_movies_title_year;
@@ -0,0 +1,32 @@
+-- Allow the same title in different release years: replace the unique title
+-- constraint with uniqueness on (normalized title, release year), where
+-- normalized means trimmed, lowercased and with runs of whitespace collapsed.
+--
+-- Titles that differed only in case or spacing used to be allowed; if two
+-- such movies share a release year the index cannot be built, so stop with a
+-- list of them instead. Rename or delete one of each pair and restart.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('%s (%s): %s', key, year, ids), '; ')
+    INTO conflicts
+    FROM (
+        SELECT lower(regexp_replace(trim(title), '\s+', ' ', 'g')) AS key,
+               EXTRACT(YEAR FROM release_date) AS year,
+               string_agg(id, ', ' ORDER BY id) AS ids
+        FROM movies
+        GROUP BY 1, 2
+        HAVING COUNT(*) > 1
+    ) duplicates;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'movies with the same normalized title and release year: %', conflicts
+            USING HINT = 'Rename or delete one movie of each group, then restart.';
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_movies_title_year
+    ON movies (lower(regexp_replace(trim(title), '\s+', ' ', 'g')), EXTRACT(YEAR FROM release_date));
+
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_title_key;
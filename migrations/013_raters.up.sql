@@ -0,0 +1,14 @@
+-- Create raters table (when each rater was first seen; unlike
+-- ratings.created_at this is never reset by an upsert or lost on delete)
+CREATE TABLE IF NOT EXISTS raters (
+    rater_id VARCHAR(100) PRIMARY KEY,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    first_rating_id INTEGER NOT NULL
+);
+
+-- Backfill from existing ratings; their created_at is the best record left
+INSERT INTO raters (rater_id, first_seen_at, first_rating_id)
+SELECT DISTINCT ON (rater_id) rater_id, created_at, id
+FROM ratings
+ORDER BY rater_id, created_at, id
+ON CONFLICT (rater_id) DO NOTHING;